@@ -29,9 +29,9 @@ func main() {
 		os.Exit(1)
 	}
 
-	minioClient, err := storage.NewMinioClient(cfg.MinioConfig)
+	objectStore, err := storage.NewObjectStore(cfg.StorageConfig, cfg.MinioConfig, cfg.SecretKey)
 	if err != nil {
-		log.Error("failed to create minio client", slog.Any("error", err))
+		log.Error("failed to create object store", slog.Any("error", err))
 		os.Exit(1)
 	}
 
@@ -57,7 +57,7 @@ func main() {
 		Cfg:         cfg,
 		DB:          db,
 		RedisClient: redisClient,
-		MinioClient: minioClient,
+		ObjectStore: objectStore,
 		Validator:   validator,
 		Logger:      log,
 	}
@@ -0,0 +1,37 @@
+// Command reconcile-reactions rebuilds the reaction counters from the
+// reactions, fixing counters that drifted from them.
+package main
+
+import (
+	"blog-api/config"
+	"blog-api/internal/database"
+	"blog-api/internal/logger"
+	"blog-api/internal/reactions"
+	"log/slog"
+	"os"
+	"time"
+)
+
+func init() {
+	time.Local = time.UTC
+}
+
+func main() {
+	cfg := config.MustGet()
+
+	log := logger.New(logger.Env(cfg.Env))
+
+	db, err := database.New(cfg.DatabaseConfig)
+	if err != nil {
+		log.Error("failed to init database", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	fixed, err := reactions.ReconcileReactionCounts(db.Get())
+	if err != nil {
+		log.Error("failed to reconcile reaction counts", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	log.Info("reaction counts reconciled", slog.Int64("fixed", fixed))
+}
@@ -0,0 +1,13 @@
+package config
+
+import "github.com/spf13/viper"
+
+type CommentsConfig struct {
+	MaxDepth int `validate:"required,min=1"`
+}
+
+func loadCommentsConfig(v *viper.Viper) CommentsConfig {
+	return CommentsConfig{
+		MaxDepth: v.GetInt("COMMENTS_MAX_DEPTH"),
+	}
+}
@@ -11,11 +11,16 @@ type Config struct {
 	SecretKey string `validate:"required"`
 	Env       string `validate:"required,oneof=local dev prod"`
 
-	ServerConfig   ServerConfig   `validate:"required"`
-	DatabaseConfig DatabaseConfig `validate:"required"`
-	JwtConfig      JwtConfig      `validate:"required"`
-	RedisConfig    RedisConfig    `validate:"required"`
-	MinioConfig    MinioConfig    `validate:"required"`
+	ServerConfig    ServerConfig    `validate:"required"`
+	DatabaseConfig  DatabaseConfig  `validate:"required"`
+	JwtConfig       JwtConfig       `validate:"required"`
+	RedisConfig     RedisConfig     `validate:"required"`
+	StorageConfig   StorageConfig   `validate:"required"`
+	MinioConfig     MinioConfig     `validate:"-"` // validated only when it is the storage backend
+	CommentsConfig  CommentsConfig  `validate:"required"`
+	MediaConfig     MediaConfig     `validate:"required"`
+	ScannerConfig   ScannerConfig   `validate:"required"`
+	ReactionsConfig ReactionsConfig `validate:"required"`
 }
 
 func MustGet() *Config {
@@ -39,13 +44,18 @@ func Get() (*Config, error) {
 	setDefaults(v)
 
 	config := &Config{
-		SecretKey:      v.GetString("SECRET_KEY"),
-		Env:            v.GetString("APP_ENV"),
-		ServerConfig:   loadServerConfig(v),
-		DatabaseConfig: loadDatabaseConfig(v),
-		JwtConfig:      loadJWTConfig(v),
-		RedisConfig:    loadRedisConfig(v),
-		MinioConfig:    loadMinioConfig(v),
+		SecretKey:       v.GetString("SECRET_KEY"),
+		Env:             v.GetString("APP_ENV"),
+		ServerConfig:    loadServerConfig(v),
+		DatabaseConfig:  loadDatabaseConfig(v),
+		JwtConfig:       loadJWTConfig(v),
+		RedisConfig:     loadRedisConfig(v),
+		StorageConfig:   loadStorageConfig(v),
+		MinioConfig:     loadMinioConfig(v),
+		CommentsConfig:  loadCommentsConfig(v),
+		MediaConfig:     loadMediaConfig(v),
+		ScannerConfig:   loadScannerConfig(v),
+		ReactionsConfig: loadReactionsConfig(v),
 	}
 
 	if err := validateConfig(config); err != nil {
@@ -60,5 +70,10 @@ func validateConfig(cfg *Config) error {
 	if err := validate.Struct(cfg); err != nil {
 		return fmt.Errorf("missing required attributes: %w", err)
 	}
+	if cfg.StorageConfig.Backend == StorageMinio {
+		if err := validate.Struct(cfg.MinioConfig); err != nil {
+			return fmt.Errorf("missing required attributes: %w", err)
+		}
+	}
 	return nil
 }
@@ -30,7 +30,34 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("REDIS_PASSWORD", "")
 	v.SetDefault("REDIS_DB", 0)
 
+	v.SetDefault("STORAGE_BACKEND", StorageMinio)
+	v.SetDefault("STORAGE_LOCAL_DIR", "./data/media")
+	v.SetDefault("STORAGE_LOCAL_URL_PREFIX", "/media")
+	v.SetDefault("STORAGE_PUBLIC_BASE_URL", "")
+	v.SetDefault("STORAGE_SIGNED_URLS", false)
+	v.SetDefault("STORAGE_SIGNED_URL_TTL", time.Hour)
+
 	v.SetDefault("MINIO_ENDPOINT", "127.0.0.1:9000")
 	v.SetDefault("MINIO_USE_SSL", false)
 	v.SetDefault("MINIO_BUCKET", "usercontent")
+
+	v.SetDefault("COMMENTS_MAX_DEPTH", 5)
+
+	v.SetDefault("REACTIONS_MAX_PER_USER", "posts:1,comments:1,users:1,media:1")
+
+	v.SetDefault("MEDIA_VARIANT_SIZES", "64,256,1024")
+	v.SetDefault("MEDIA_JPEG_QUALITY", 85)
+	v.SetDefault("MEDIA_MAX_PIXELS", 40_000_000)
+	v.SetDefault("MEDIA_UPLOAD_TTL", 15*time.Minute)
+	v.SetDefault("MEDIA_UPLOAD_CLEANUP_INTERVAL", 10*time.Minute)
+	v.SetDefault("MEDIA_UNATTACHED_TTL", 24*time.Hour)
+	v.SetDefault("MEDIA_AVATAR_HISTORY_SIZE", 0)
+	v.SetDefault("MEDIA_GC_INTERVAL", 6*time.Hour)
+	v.SetDefault("MEDIA_GC_GRACE_PERIOD", 24*time.Hour)
+	v.SetDefault("MEDIA_GC_DRY_RUN", false)
+	v.SetDefault("MEDIA_QUOTAS", "user:524288000,admin:0")
+
+	v.SetDefault("SCANNER_BACKEND", ScannerNone)
+	v.SetDefault("SCANNER_CLAMAV_ADDRESS", "tcp://127.0.0.1:3310")
+	v.SetDefault("SCANNER_TIMEOUT", 30*time.Second)
 }
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+type MediaConfig struct {
+	// VariantSizes are the bounding boxes, in pixels, of the resized copies
+	// generated for every uploaded image.
+	VariantSizes []int `validate:"required,dive,min=16,max=4096"`
+	JPEGQuality  int   `validate:"required,min=1,max=100"`
+	// MaxPixels caps width*height of a decoded upload.
+	MaxPixels int `validate:"required,min=1"`
+	// UploadTTL is how long a direct upload slot stays valid. Slots that are
+	// not confirmed in time are removed together with their objects every
+	// UploadCleanupInterval.
+	UploadTTL             time.Duration `validate:"required"`
+	UploadCleanupInterval time.Duration `validate:"required"`
+	// UnattachedMediaTTL is how long post media may stay unattached to any
+	// post before the upload cleanup deletes it.
+	UnattachedMediaTTL time.Duration `validate:"required"`
+
+	// AvatarHistorySize is how many replaced avatars are kept per user to
+	// revert to. 0 deletes avatars as soon as they are replaced. It is kept
+	// small because avatars do not count against the media quotas.
+	AvatarHistorySize int `validate:"min=0,max=10"`
+
+	// The garbage collector deletes objects no row refers to every
+	// GCInterval, once they are older than GCGracePeriod. With GCDryRun it
+	// only reports them.
+	GCInterval    time.Duration `validate:"required"`
+	GCGracePeriod time.Duration `validate:"required"`
+	GCDryRun      bool
+
+	// Quotas caps the bytes of media each role may store, 0 meaning no limit.
+	Quotas map[string]int64 `validate:"required,dive,keys,oneof=user admin,endkeys,min=0"`
+}
+
+func loadMediaConfig(v *viper.Viper) MediaConfig {
+	return MediaConfig{
+		VariantSizes: parseIntList(v.GetString("MEDIA_VARIANT_SIZES")),
+		JPEGQuality:  v.GetInt("MEDIA_JPEG_QUALITY"),
+		MaxPixels:    v.GetInt("MEDIA_MAX_PIXELS"),
+
+		UploadTTL:             v.GetDuration("MEDIA_UPLOAD_TTL"),
+		UploadCleanupInterval: v.GetDuration("MEDIA_UPLOAD_CLEANUP_INTERVAL"),
+		UnattachedMediaTTL:    v.GetDuration("MEDIA_UNATTACHED_TTL"),
+
+		AvatarHistorySize: v.GetInt("MEDIA_AVATAR_HISTORY_SIZE"),
+
+		GCInterval:    v.GetDuration("MEDIA_GC_INTERVAL"),
+		GCGracePeriod: v.GetDuration("MEDIA_GC_GRACE_PERIOD"),
+		GCDryRun:      v.GetBool("MEDIA_GC_DRY_RUN"),
+
+		Quotas: parseIntMap[int64](v.GetString("MEDIA_QUOTAS")),
+	}
+}
+
+// parseIntList parses a comma separated list. Malformed items become 0 so
+// that validation reports them instead of them being silently dropped.
+func parseIntList(s string) []int {
+	var values []int
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		n, _ := strconv.Atoi(item)
+		values = append(values, n)
+	}
+	return values
+}
+
+// parseIntMap parses a comma separated list of key:value pairs. Malformed
+// values become -1 so that validation reports them.
+func parseIntMap[T int | int64](s string) map[string]T {
+	values := make(map[string]T)
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(item, ":")
+		n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			n = -1
+		}
+		values[strings.TrimSpace(key)] = T(n)
+	}
+	return values
+}
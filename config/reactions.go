@@ -0,0 +1,16 @@
+package config
+
+import "github.com/spf13/viper"
+
+type ReactionsConfig struct {
+	// MaxPerUser is how many distinct reaction types a user may leave on a
+	// single target, per target type. With 1, a new reaction replaces the
+	// previous one.
+	MaxPerUser map[string]int `validate:"required,dive,keys,oneof=posts comments users media,endkeys,min=1,max=20"`
+}
+
+func loadReactionsConfig(v *viper.Viper) ReactionsConfig {
+	return ReactionsConfig{
+		MaxPerUser: parseIntMap[int](v.GetString("REACTIONS_MAX_PER_USER")),
+	}
+}
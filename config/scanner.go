@@ -0,0 +1,30 @@
+package config
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	ScannerNone   = "none"
+	ScannerClamAV = "clamav"
+)
+
+type ScannerConfig struct {
+	Backend string `validate:"required,oneof=none clamav"`
+	// ClamAVAddress is where clamd listens, as tcp://host:port or
+	// unix:///path/to/clamd.sock. Its StreamMaxLength must allow the largest
+	// upload, or such uploads fail to be scanned.
+	ClamAVAddress string `validate:"required_if=Backend clamav"`
+	// Timeout bounds a single scan, including the connection to the scanner.
+	Timeout time.Duration `validate:"required"`
+}
+
+func loadScannerConfig(v *viper.Viper) ScannerConfig {
+	return ScannerConfig{
+		Backend:       v.GetString("SCANNER_BACKEND"),
+		ClamAVAddress: v.GetString("SCANNER_CLAMAV_ADDRESS"),
+		Timeout:       v.GetDuration("SCANNER_TIMEOUT"),
+	}
+}
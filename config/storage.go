@@ -0,0 +1,42 @@
+package config
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	StorageMinio  = "minio"
+	StorageLocal  = "local"
+	StorageMemory = "memory"
+)
+
+type StorageConfig struct {
+	Backend string `validate:"required,oneof=minio local memory"`
+	// LocalDir is where the local backend keeps objects, served by the API
+	// under LocalURLPrefix.
+	LocalDir       string `validate:"required_if=Backend local"`
+	LocalURLPrefix string `validate:"required_if=Backend local,omitempty,startswith=/"`
+
+	// PublicBaseURL replaces the backend address in object URLs, e.g. a CDN
+	// or an nginx location in front of the bucket. Object keys are appended
+	// to it.
+	PublicBaseURL string `validate:"omitempty,url"`
+	// SignedURLs makes every object URL a time-limited signed one, valid for
+	// SignedURLTTL. MinIO signs against its own endpoint, so PublicBaseURL is
+	// not used for them.
+	SignedURLs   bool
+	SignedURLTTL time.Duration `validate:"required"`
+}
+
+func loadStorageConfig(v *viper.Viper) StorageConfig {
+	return StorageConfig{
+		Backend:        v.GetString("STORAGE_BACKEND"),
+		LocalDir:       v.GetString("STORAGE_LOCAL_DIR"),
+		LocalURLPrefix: v.GetString("STORAGE_LOCAL_URL_PREFIX"),
+		PublicBaseURL:  v.GetString("STORAGE_PUBLIC_BASE_URL"),
+		SignedURLs:     v.GetBool("STORAGE_SIGNED_URLS"),
+		SignedURLTTL:   v.GetDuration("STORAGE_SIGNED_URL_TTL"),
+	}
+}
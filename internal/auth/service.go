@@ -125,6 +125,7 @@ func (s *authService) Register(ctx context.Context, input RegisterUserInput) (*T
 	user = models.User{
 		Username: input.Username,
 		Password: hashedPassword,
+		Role:     models.RoleUser,
 	}
 
 	if err = db.Create(&user).Error; err != nil {
@@ -0,0 +1,39 @@
+package bookmarks
+
+import (
+	"blog-api/internal/posts"
+	"time"
+)
+
+type AddBookmarkInput struct {
+	PostID       uint  `json:"post_id" validate:"required"`
+	CollectionID *uint `json:"collection_id" validate:"omitempty,min=1"`
+}
+
+type CollectionInput struct {
+	Name string `json:"name" validate:"required,min=1,max=100"`
+}
+
+type ListParams struct {
+	CollectionID uint   `query:"collection_id"`
+	Limit        int    `query:"limit" validate:"omitempty,min=1,max=100"`
+	Cursor       string `query:"cursor" validate:"omitempty,max=512"`
+}
+
+type BookmarkResponse struct {
+	PostID       uint      `json:"post_id"`
+	CollectionID *uint     `json:"collection_id"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type CollectionResponse struct {
+	ID             uint      `json:"id"`
+	Name           string    `json:"name"`
+	BookmarksCount int64     `json:"bookmarks_count"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type ListResponse struct {
+	NextCursor string                `json:"next_cursor,omitempty"`
+	Result     []*posts.PostResponse `json:"result"`
+}
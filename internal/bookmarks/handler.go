@@ -0,0 +1,178 @@
+package bookmarks
+
+import (
+	"blog-api/internal/errors"
+	"blog-api/internal/logger"
+	"blog-api/internal/users"
+	"blog-api/pkg/response"
+	"context"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/requestid"
+)
+
+type IBookmarkHandler interface {
+	AddBookmark(ctx fiber.Ctx) error
+	RemoveBookmark(ctx fiber.Ctx) error
+	GetBookmarks(ctx fiber.Ctx) error
+
+	CreateCollection(ctx fiber.Ctx) error
+	GetCollections(ctx fiber.Ctx) error
+	RenameCollection(ctx fiber.Ctx) error
+	DeleteCollection(ctx fiber.Ctx) error
+}
+
+type bookmarkHandler struct {
+	bookmarkService IBookmarkService
+}
+
+func NewBookmarkHandler(bookmarkService IBookmarkService) IBookmarkHandler {
+	return &bookmarkHandler{
+		bookmarkService: bookmarkService,
+	}
+}
+
+func (h *bookmarkHandler) AddBookmark(ctx fiber.Ctx) error {
+	user := users.MustGetUser(ctx)
+	requestID := requestid.FromContext(ctx)
+
+	var input AddBookmarkInput
+	if err := ctx.Bind().JSON(&input); err != nil {
+		return err
+	}
+
+	res, err := h.bookmarkService.AddBookmark(
+		context.WithValue(ctx, logger.RequestIDKey, requestID),
+		user.UserID,
+		input,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(response.NewResponse(res))
+}
+
+func (h *bookmarkHandler) RemoveBookmark(ctx fiber.Ctx) error {
+	user := users.MustGetUser(ctx)
+	postID := fiber.Params[uint](ctx, "post_id")
+
+	requestID := requestid.FromContext(ctx)
+
+	err := h.bookmarkService.RemoveBookmark(
+		context.WithValue(ctx, logger.RequestIDKey, requestID),
+		user.UserID,
+		postID,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *bookmarkHandler) GetBookmarks(ctx fiber.Ctx) error {
+	user := users.MustGetUser(ctx)
+	requestID := requestid.FromContext(ctx)
+
+	var params ListParams
+	if err := ctx.Bind().Query(&params); err != nil {
+		return errors.ErrInvalidQuery
+	}
+
+	data, err := h.bookmarkService.GetBookmarks(
+		context.WithValue(ctx, logger.RequestIDKey, requestID),
+		user.UserID,
+		params,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(response.NewCursorPaginatedResponse(nil, data.NextCursor, "", data.Result))
+}
+
+func (h *bookmarkHandler) CreateCollection(ctx fiber.Ctx) error {
+	user := users.MustGetUser(ctx)
+	requestID := requestid.FromContext(ctx)
+
+	var input CollectionInput
+	if err := ctx.Bind().JSON(&input); err != nil {
+		return err
+	}
+
+	res, err := h.bookmarkService.CreateCollection(
+		context.WithValue(ctx, logger.RequestIDKey, requestID),
+		user.UserID,
+		input,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(response.NewResponse(res))
+}
+
+func (h *bookmarkHandler) GetCollections(ctx fiber.Ctx) error {
+	user := users.MustGetUser(ctx)
+	requestID := requestid.FromContext(ctx)
+
+	res, err := h.bookmarkService.GetCollections(
+		context.WithValue(ctx, logger.RequestIDKey, requestID),
+		user.UserID,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(response.NewResponse(res))
+}
+
+func (h *bookmarkHandler) RenameCollection(ctx fiber.Ctx) error {
+	user := users.MustGetUser(ctx)
+	collectionID := fiber.Params[uint](ctx, "id")
+
+	requestID := requestid.FromContext(ctx)
+
+	var input CollectionInput
+	if err := ctx.Bind().JSON(&input); err != nil {
+		return err
+	}
+
+	res, err := h.bookmarkService.RenameCollection(
+		context.WithValue(ctx, logger.RequestIDKey, requestID),
+		user.UserID,
+		collectionID,
+		input,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(response.NewResponse(res))
+}
+
+func (h *bookmarkHandler) DeleteCollection(ctx fiber.Ctx) error {
+	user := users.MustGetUser(ctx)
+	collectionID := fiber.Params[uint](ctx, "id")
+
+	requestID := requestid.FromContext(ctx)
+
+	err := h.bookmarkService.DeleteCollection(
+		context.WithValue(ctx, logger.RequestIDKey, requestID),
+		user.UserID,
+		collectionID,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
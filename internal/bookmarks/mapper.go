@@ -0,0 +1,32 @@
+package bookmarks
+
+import "blog-api/internal/models"
+
+func MapBookmarkToResponse(bookmark models.Bookmark) *BookmarkResponse {
+	return &BookmarkResponse{
+		PostID:       bookmark.PostID,
+		CollectionID: bookmark.CollectionID,
+		CreatedAt:    bookmark.CreatedAt,
+	}
+}
+
+func MapCollectionsToResponse(collections []models.BookmarkCollection) []*CollectionResponse {
+	output := make([]*CollectionResponse, len(collections))
+	for i, collection := range collections {
+		output[i] = MapCollectionToResponse(collection)
+	}
+	return output
+}
+
+func MapCollectionToResponse(collection models.BookmarkCollection) *CollectionResponse {
+	if collection.ID == 0 {
+		return nil
+	}
+
+	return &CollectionResponse{
+		ID:             collection.ID,
+		Name:           collection.Name,
+		BookmarksCount: collection.BookmarksCount,
+		CreatedAt:      collection.CreatedAt,
+	}
+}
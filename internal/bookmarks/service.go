@@ -0,0 +1,293 @@
+package bookmarks
+
+import (
+	"blog-api/internal/database"
+	"blog-api/internal/errors"
+	"blog-api/internal/logger"
+	"blog-api/internal/models"
+	"blog-api/internal/posts"
+	"blog-api/pkg/cursor"
+	"context"
+	goerrors "errors"
+	"log/slog"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IBookmarkService interface {
+	AddBookmark(ctx context.Context, userID uint, input AddBookmarkInput) (*BookmarkResponse, error)
+	RemoveBookmark(ctx context.Context, userID uint, postID uint) error
+	GetBookmarks(ctx context.Context, userID uint, params ListParams) (*ListResponse, error)
+
+	CreateCollection(ctx context.Context, userID uint, input CollectionInput) (*CollectionResponse, error)
+	GetCollections(ctx context.Context, userID uint) ([]*CollectionResponse, error)
+	RenameCollection(ctx context.Context, userID uint, collectionID uint, input CollectionInput) (*CollectionResponse, error)
+	DeleteCollection(ctx context.Context, userID uint, collectionID uint) error
+}
+
+type bookmarkService struct {
+	db          *database.DB
+	postService posts.IPostService
+	logger      *slog.Logger
+}
+
+func NewBookmarkService(db *database.DB, postService posts.IPostService, logger *slog.Logger) IBookmarkService {
+	return &bookmarkService{
+		db:          db,
+		postService: postService,
+		logger:      logger,
+	}
+}
+
+func (s *bookmarkService) AddBookmark(ctx context.Context, userID uint, input AddBookmarkInput) (*BookmarkResponse, error) {
+	db := s.db.WithContext(ctx)
+	log := logger.WithUserID(logger.FromCtx(ctx, s.logger), userID).With(slog.Uint64("post_id", uint64(input.PostID)))
+
+	log.Info("adding bookmark")
+
+	if err := db.Select("id").First(&models.Post{}, input.PostID).Error; err != nil {
+		if goerrors.Is(err, database.ErrRecordNotFound) {
+			log.Warn("post not found")
+			return nil, errors.ErrNotFound
+		}
+		log.Error("failed to fetch post from database", logger.Err(err))
+		return nil, err
+	}
+
+	if input.CollectionID != nil {
+		if _, err := s.getCollection(db, log, userID, *input.CollectionID); err != nil {
+			return nil, err
+		}
+	}
+
+	bookmark := models.Bookmark{
+		UserID:       userID,
+		PostID:       input.PostID,
+		CollectionID: input.CollectionID,
+	}
+
+	// bookmarking an already saved post moves it to the given collection
+	err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "post_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"collection_id", "updated_at"}),
+	}).Create(&bookmark).Error
+	if err != nil {
+		log.Error("failed to save bookmark", logger.Err(err))
+		return nil, err
+	}
+
+	if err := db.Where("user_id = ? AND post_id = ?", userID, input.PostID).First(&bookmark).Error; err != nil {
+		log.Error("failed to fetch bookmark from database", logger.Err(err))
+		return nil, err
+	}
+
+	log.Info("bookmark saved successfully")
+
+	return MapBookmarkToResponse(bookmark), nil
+}
+
+func (s *bookmarkService) RemoveBookmark(ctx context.Context, userID uint, postID uint) error {
+	db := s.db.WithContext(ctx)
+	log := logger.WithUserID(logger.FromCtx(ctx, s.logger), userID).With(slog.Uint64("post_id", uint64(postID)))
+
+	log.Info("removing bookmark")
+
+	res := db.Where("user_id = ? AND post_id = ?", userID, postID).Delete(&models.Bookmark{})
+	if res.Error != nil {
+		log.Error("failed to delete bookmark", logger.Err(res.Error))
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		log.Warn("bookmark not found")
+		return errors.ErrNotFound
+	}
+
+	log.Info("bookmark removed successfully")
+
+	return nil
+}
+
+func (s *bookmarkService) GetBookmarks(ctx context.Context, userID uint, params ListParams) (*ListResponse, error) {
+	db := s.db.WithContext(ctx)
+	log := logger.WithUserID(logger.FromCtx(ctx, s.logger), userID)
+
+	log.Info("fetching bookmarks")
+	log.Debug("list parameters", slog.Any("params", params))
+
+	limit := posts.NormalizeLimit(params.Limit)
+
+	query := db.Where("user_id = ?", userID).
+		Order("created_at DESC, id DESC").
+		Limit(limit + 1)
+
+	if params.CollectionID != 0 {
+		if _, err := s.getCollection(db, log, userID, params.CollectionID); err != nil {
+			return nil, err
+		}
+		query = query.Where("collection_id = ?", params.CollectionID)
+	}
+
+	if params.Cursor != "" {
+		c, err := cursor.Decode[cursor.CreatedAtID](params.Cursor)
+		if err != nil {
+			log.Warn("failed to decode cursor", logger.Err(err))
+			return nil, errors.ErrInvalidCursor
+		}
+		query = query.Where("(created_at, id) < (?, ?)", c.CreatedAt, c.ID)
+	}
+
+	var bookmarks []models.Bookmark
+	if err := query.Find(&bookmarks).Error; err != nil {
+		log.Error("failed to fetch bookmarks from database", logger.Err(err))
+		return nil, err
+	}
+
+	listResponse := &ListResponse{}
+	if len(bookmarks) > limit {
+		bookmarks = bookmarks[:limit]
+
+		last := bookmarks[len(bookmarks)-1]
+		nextCursor, err := cursor.Encode(cursor.CreatedAtID{CreatedAt: last.CreatedAt, ID: last.ID})
+		if err != nil {
+			log.Error("failed to encode next cursor", logger.Err(err))
+			return nil, err
+		}
+		listResponse.NextCursor = nextCursor
+	}
+
+	postIDs := make([]uint, len(bookmarks))
+	for i, b := range bookmarks {
+		postIDs[i] = b.PostID
+	}
+
+	result, err := s.postService.GetPostsByIDs(ctx, postIDs, &userID)
+	if err != nil {
+		return nil, err
+	}
+	listResponse.Result = result
+
+	log.Info("bookmarks retrieved successfully", slog.Int("returned", len(result)))
+
+	return listResponse, nil
+}
+
+func (s *bookmarkService) CreateCollection(ctx context.Context, userID uint, input CollectionInput) (*CollectionResponse, error) {
+	db := s.db.WithContext(ctx)
+	log := logger.WithUserID(logger.FromCtx(ctx, s.logger), userID)
+
+	log.Info("creating bookmark collection")
+
+	if err := s.ensureNameAvailable(db, log, userID, input.Name); err != nil {
+		return nil, err
+	}
+
+	collection := models.BookmarkCollection{
+		UserID: userID,
+		Name:   input.Name,
+	}
+	if err := db.Create(&collection).Error; err != nil {
+		log.Error("failed to create bookmark collection", logger.Err(err))
+		return nil, err
+	}
+
+	log.Info("bookmark collection created successfully", slog.Uint64("collection_id", uint64(collection.ID)))
+
+	return MapCollectionToResponse(collection), nil
+}
+
+func (s *bookmarkService) GetCollections(ctx context.Context, userID uint) ([]*CollectionResponse, error) {
+	db := s.db.WithContext(ctx)
+	log := logger.WithUserID(logger.FromCtx(ctx, s.logger), userID)
+
+	var collections []models.BookmarkCollection
+	err := db.Select("bookmark_collections.*, (SELECT COUNT(*) FROM bookmarks b WHERE b.collection_id = bookmark_collections.id) AS bookmarks_count").
+		Where("user_id = ?", userID).
+		Order("name ASC").
+		Find(&collections).Error
+	if err != nil {
+		log.Error("failed to get bookmark collections", logger.Err(err))
+		return nil, err
+	}
+
+	return MapCollectionsToResponse(collections), nil
+}
+
+func (s *bookmarkService) RenameCollection(ctx context.Context, userID uint, collectionID uint, input CollectionInput) (*CollectionResponse, error) {
+	db := s.db.WithContext(ctx)
+	log := logger.WithUserID(logger.FromCtx(ctx, s.logger), userID).With(slog.Uint64("collection_id", uint64(collectionID)))
+
+	log.Info("renaming bookmark collection")
+
+	collection, err := s.getCollection(db, log, userID, collectionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if collection.Name != input.Name {
+		if err := s.ensureNameAvailable(db, log, userID, input.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := db.Model(collection).Update("name", input.Name).Error; err != nil {
+		log.Error("failed to rename bookmark collection", logger.Err(err))
+		return nil, err
+	}
+
+	log.Info("bookmark collection renamed successfully")
+
+	return MapCollectionToResponse(*collection), nil
+}
+
+func (s *bookmarkService) DeleteCollection(ctx context.Context, userID uint, collectionID uint) error {
+	db := s.db.WithContext(ctx)
+	log := logger.WithUserID(logger.FromCtx(ctx, s.logger), userID).With(slog.Uint64("collection_id", uint64(collectionID)))
+
+	log.Info("deleting bookmark collection")
+
+	collection, err := s.getCollection(db, log, userID, collectionID)
+	if err != nil {
+		return err
+	}
+
+	// bookmarks of the collection are kept and become unsorted
+	if err := db.Delete(collection).Error; err != nil {
+		log.Error("failed to delete bookmark collection", logger.Err(err))
+		return err
+	}
+
+	log.Info("bookmark collection deleted successfully")
+
+	return nil
+}
+
+// getCollection loads a collection of the given user. Collections are
+// private, so someone else's collection is reported as not found.
+func (s *bookmarkService) getCollection(db *gorm.DB, log *slog.Logger, userID uint, collectionID uint) (*models.BookmarkCollection, error) {
+	var collection models.BookmarkCollection
+	if err := db.Where("id = ? AND user_id = ?", collectionID, userID).First(&collection).Error; err != nil {
+		if goerrors.Is(err, database.ErrRecordNotFound) {
+			log.Warn("bookmark collection not found", slog.Uint64("collection_id", uint64(collectionID)))
+			return nil, errors.ErrNotFound
+		}
+		log.Error("failed to fetch bookmark collection", logger.Err(err))
+		return nil, err
+	}
+	return &collection, nil
+}
+
+func (s *bookmarkService) ensureNameAvailable(db *gorm.DB, log *slog.Logger, userID uint, name string) error {
+	var count int64
+	if err := db.Model(&models.BookmarkCollection{}).Where("user_id = ? AND name = ?", userID, name).Count(&count).Error; err != nil {
+		log.Error("failed to check bookmark collection name", logger.Err(err))
+		return err
+	}
+
+	if count > 0 {
+		log.Info("bookmark collection name already taken")
+		return errors.ErrCollectionAlreadyExists
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package comments
+
+import (
+	"blog-api/internal/models"
+	"blog-api/internal/posts"
+	"blog-api/internal/users"
+	"time"
+)
+
+type CreateCommentInput struct {
+	PostID     uint   `json:"post_id" validate:"required"`
+	ParentID   *uint  `json:"parent_id" validate:"omitempty,min=1"`
+	Content    string `json:"content" validate:"required,min=1,max=10000"`
+	OffsetUnit string `json:"offset_unit" validate:"omitempty,oneof=utf16 codepoint byte"`
+
+	Entities []posts.PostEntityInput `json:"entities" validate:"omitempty,dive"`
+}
+
+type UpdateCommentInput struct {
+	Content    string `json:"content" validate:"required,min=1,max=10000"`
+	OffsetUnit string `json:"offset_unit" validate:"omitempty,oneof=utf16 codepoint byte"`
+
+	Entities []posts.PostEntityInput `json:"entities" validate:"omitempty,dive"`
+}
+
+type CommentResponse struct {
+	ID         uint       `json:"id"`
+	PostID     uint       `json:"post_id"`
+	ParentID   *uint      `json:"parent_id"`
+	AuthorID   uint       `json:"author_id"`
+	Depth      int        `json:"depth"`
+	Content    string     `json:"content"`
+	OffsetUnit string     `json:"offset_unit"`
+	Deleted    bool       `json:"deleted"`
+	CreatedAt  time.Time  `json:"created_at"`
+	EditedAt   *time.Time `json:"edited_at,omitempty"`
+
+	Author        *users.UserResponse      `json:"author,omitempty"`
+	Entities      []*posts.PostEntityInput `json:"entities"`
+	RepliesCount  int64                    `json:"replies_count"`
+	UserReactions []models.UserReaction    `json:"user_reactions,omitempty"`
+	Reactions     []models.ReactionStat    `json:"reactions"`
+}
+
+type ListResponse struct {
+	NextCursor string             `json:"next_cursor,omitempty"`
+	Result     []*CommentResponse `json:"result"`
+}
@@ -0,0 +1,156 @@
+package comments
+
+import (
+	"blog-api/internal/errors"
+	"blog-api/internal/logger"
+	"blog-api/internal/users"
+	"blog-api/pkg/response"
+	"context"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/requestid"
+)
+
+type ICommentHandler interface {
+	CreateComment(ctx fiber.Ctx) error
+	GetComment(ctx fiber.Ctx) error
+	GetComments(ctx fiber.Ctx) error
+	UpdateComment(ctx fiber.Ctx) error
+	DeleteComment(ctx fiber.Ctx) error
+}
+
+type commentHandler struct {
+	commentService ICommentService
+}
+
+func NewCommentHandler(commentService ICommentService) ICommentHandler {
+	return &commentHandler{
+		commentService: commentService,
+	}
+}
+
+func (h *commentHandler) CreateComment(ctx fiber.Ctx) error {
+	user := users.MustGetUser(ctx)
+
+	requestID := requestid.FromContext(ctx)
+
+	var input CreateCommentInput
+	if err := ctx.Bind().JSON(&input); err != nil {
+		return err
+	}
+
+	comment, err := h.commentService.CreateComment(
+		context.WithValue(ctx, logger.RequestIDKey, requestID),
+		user.UserID,
+		input,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(response.Response[*CommentResponse]{
+		OK:   true,
+		Data: comment,
+	})
+}
+
+func (h *commentHandler) GetComment(ctx fiber.Ctx) error {
+	commentID := fiber.Params[uint](ctx, "id")
+	requestID := requestid.FromContext(ctx)
+
+	user := users.GetUser(ctx)
+	var userID *uint
+	if user != nil {
+		userID = &user.UserID
+	}
+
+	comment, err := h.commentService.GetComment(
+		context.WithValue(ctx, logger.RequestIDKey, requestID),
+		commentID,
+		userID,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(response.Response[*CommentResponse]{
+		OK:   true,
+		Data: comment,
+	})
+}
+
+func (h *commentHandler) GetComments(ctx fiber.Ctx) error {
+	requestID := requestid.FromContext(ctx)
+
+	user := users.GetUser(ctx)
+	var userID *uint
+	if user != nil {
+		userID = &user.UserID
+	}
+
+	var params ListParams
+	if err := ctx.Bind().Query(&params); err != nil {
+		return errors.ErrInvalidQuery
+	}
+
+	data, err := h.commentService.GetComments(
+		context.WithValue(ctx, logger.RequestIDKey, requestID),
+		params,
+		userID,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(response.NewCursorPaginatedResponse(nil, data.NextCursor, "", data.Result))
+}
+
+func (h *commentHandler) UpdateComment(ctx fiber.Ctx) error {
+	user := users.MustGetUser(ctx)
+	commentID := fiber.Params[uint](ctx, "id")
+
+	requestID := requestid.FromContext(ctx)
+
+	var input UpdateCommentInput
+	if err := ctx.Bind().JSON(&input); err != nil {
+		return err
+	}
+
+	comment, err := h.commentService.UpdateComment(
+		context.WithValue(ctx, logger.RequestIDKey, requestID),
+		user.UserID,
+		commentID,
+		input,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(response.Response[*CommentResponse]{
+		OK:   true,
+		Data: comment,
+	})
+}
+
+func (h *commentHandler) DeleteComment(ctx fiber.Ctx) error {
+	user := users.MustGetUser(ctx)
+	commentID := fiber.Params[uint](ctx, "id")
+
+	requestID := requestid.FromContext(ctx)
+
+	err := h.commentService.DeleteComment(
+		context.WithValue(ctx, logger.RequestIDKey, requestID),
+		user.UserID,
+		commentID,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
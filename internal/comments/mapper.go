@@ -0,0 +1,73 @@
+package comments
+
+import (
+	"blog-api/internal/models"
+	"blog-api/internal/posts"
+	"blog-api/internal/users"
+)
+
+func MapCommentToResponse(comment models.Comment) *CommentResponse {
+	if comment.ID == 0 {
+		return nil
+	}
+
+	res := &CommentResponse{
+		ID:           comment.ID,
+		PostID:       comment.PostID,
+		ParentID:     comment.ParentID,
+		AuthorID:     comment.AuthorID,
+		Depth:        comment.Depth,
+		OffsetUnit:   posts.OffsetUnit,
+		CreatedAt:    comment.CreatedAt,
+		Entities:     []*posts.PostEntityInput{},
+		RepliesCount: comment.RepliesCount,
+	}
+
+	// deleted comments stay in the thread as placeholders for their replies
+	if comment.DeletedAt.Valid {
+		res.Deleted = true
+		return res
+	}
+
+	res.Content = comment.Content
+	res.EditedAt = comment.EditedAt
+	res.Author = users.MapUserToResponse(comment.Author)
+	res.Entities = MapEntitiesToResponse(comment.Entities)
+	res.UserReactions = comment.UserReactions
+	res.Reactions = comment.Reactions
+	return res
+}
+
+func MapEntitiesToResponse(entities []models.CommentEntity) []*posts.PostEntityInput {
+	output := make([]*posts.PostEntityInput, len(entities))
+	for i, entity := range entities {
+		output[i] = posts.MapEntityToInput(entity.Entity)
+	}
+	return output
+}
+
+func MapInputsToCommentEntity(inputs []posts.PostEntityInput) []models.CommentEntity {
+	entities := make([]models.CommentEntity, len(inputs))
+	for i, input := range inputs {
+		entities[i] = models.CommentEntity{
+			Entity: posts.MapInputToEntity(input),
+		}
+	}
+	return entities
+}
+
+func commentEntities(entities []models.CommentEntity) []models.Entity {
+	output := make([]models.Entity, len(entities))
+	for i, entity := range entities {
+		output[i] = entity.Entity
+	}
+	return output
+}
+
+func commentEntityRefs(entities []models.CommentEntity) []*models.Entity {
+	refs := make([]*models.Entity, len(entities))
+	for i := range entities {
+		refs[i] = &entities[i].Entity
+	}
+	return refs
+}
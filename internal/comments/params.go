@@ -0,0 +1,8 @@
+package comments
+
+type ListParams struct {
+	PostID   uint   `query:"post_id" validate:"required"`
+	ParentID uint   `query:"parent_id"`
+	Limit    int    `query:"limit" validate:"omitempty,min=1,max=100"`
+	Cursor   string `query:"cursor" validate:"omitempty,max=512"`
+}
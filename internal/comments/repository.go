@@ -0,0 +1,43 @@
+package comments
+
+import (
+	"blog-api/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// visibleComment keeps live comments, and deleted ones as placeholders while
+// a live reply, however deep, is still reached through them.
+const visibleComment = `(comments.deleted_at IS NULL OR EXISTS (
+	WITH RECURSIVE descendants AS (
+		SELECT c.id, c.deleted_at FROM comments c WHERE c.parent_id = comments.id
+		UNION ALL
+		SELECT c.id, c.deleted_at FROM comments c JOIN descendants d ON c.parent_id = d.id
+	)
+	SELECT 1 FROM descendants WHERE deleted_at IS NULL
+))`
+
+// GetRepliesCounts counts the direct replies of each comment that are listed
+// in its thread, placeholders included.
+func GetRepliesCounts(db *gorm.DB, commentIDs []uint) (map[uint]int64, error) {
+	var results []struct {
+		ParentID uint
+		Count    int64
+	}
+
+	err := db.Unscoped().Model(&models.Comment{}).
+		Select("parent_id, COUNT(*) as count").
+		Where("parent_id IN ?", commentIDs).
+		Where(visibleComment).
+		Group("parent_id").
+		Scan(&results).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uint]int64, len(results))
+	for _, r := range results {
+		counts[r.ParentID] = r.Count
+	}
+	return counts, nil
+}
@@ -0,0 +1,403 @@
+package comments
+
+import (
+	"blog-api/config"
+	"blog-api/internal/database"
+	"blog-api/internal/errors"
+	"blog-api/internal/logger"
+	"blog-api/internal/models"
+	"blog-api/internal/posts"
+	"blog-api/internal/reactions"
+	"blog-api/internal/storage"
+	"blog-api/internal/users"
+	"blog-api/pkg/cursor"
+	"context"
+	goerrors "errors"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ICommentService interface {
+	CreateComment(ctx context.Context, userID uint, input CreateCommentInput) (*CommentResponse, error)
+	GetComment(ctx context.Context, commentID uint, userID *uint) (*CommentResponse, error)
+	GetComments(ctx context.Context, params ListParams, userID *uint) (*ListResponse, error)
+	UpdateComment(ctx context.Context, userID uint, commentID uint, input UpdateCommentInput) (*CommentResponse, error)
+	DeleteComment(ctx context.Context, userID uint, commentID uint) error
+}
+
+type commentService struct {
+	db       *database.DB
+	urls     storage.URLResolver
+	maxDepth int
+	logger   *slog.Logger
+}
+
+func NewCommentService(db *database.DB, urls storage.URLResolver, cfg config.CommentsConfig, logger *slog.Logger) ICommentService {
+	return &commentService{
+		db:       db,
+		urls:     urls,
+		maxDepth: cfg.MaxDepth,
+		logger:   logger,
+	}
+}
+
+func (s *commentService) CreateComment(ctx context.Context, userID uint, input CreateCommentInput) (*CommentResponse, error) {
+	db := s.db.WithContext(ctx)
+	log := logger.WithUserID(logger.FromCtx(ctx, s.logger), userID).With(slog.Uint64("post_id", uint64(input.PostID)))
+
+	log.Info("creating new comment")
+	log.Debug("comment input data", slog.Any("input", input))
+
+	if err := db.Select("id").First(&models.Post{}, input.PostID).Error; err != nil {
+		if goerrors.Is(err, database.ErrRecordNotFound) {
+			log.Warn("post not found")
+			return nil, errors.ErrNotFound
+		}
+		log.Error("failed to fetch post from database", logger.Err(err))
+		return nil, err
+	}
+
+	inputEntities, err := posts.ConvertEntityOffsets(input.Content, input.Entities, input.OffsetUnit)
+	if err != nil {
+		log.Warn("entity offset conversion failed", logger.Err(err))
+		return nil, errors.BadRequest(err.Error())
+	}
+
+	comment := models.Comment{
+		PostID:     input.PostID,
+		AuthorID:   userID,
+		Content:    input.Content,
+		OffsetUnit: posts.OffsetUnit,
+		Entities:   MapInputsToCommentEntity(inputEntities),
+	}
+
+	if input.ParentID != nil {
+		var parent models.Comment
+		if err := db.First(&parent, *input.ParentID).Error; err != nil {
+			if goerrors.Is(err, database.ErrRecordNotFound) {
+				log.Warn("parent comment not found", slog.Uint64("parent_id", uint64(*input.ParentID)))
+				return nil, errors.BadRequest("parent comment not found")
+			}
+			log.Error("failed to fetch parent comment", logger.Err(err))
+			return nil, err
+		}
+
+		if parent.PostID != input.PostID {
+			log.Warn("parent comment belongs to another post", slog.Uint64("parent_id", uint64(parent.ID)))
+			return nil, errors.BadRequest("parent comment belongs to another post")
+		}
+
+		if parent.Depth+1 >= s.maxDepth {
+			log.Warn("comment thread is too deep", slog.Int("depth", parent.Depth+1))
+			return nil, errors.ErrCommentTooDeep
+		}
+
+		comment.ParentID = &parent.ID
+		comment.Depth = parent.Depth + 1
+	}
+
+	if err := posts.ValidateEntities(comment.Content, commentEntities(comment.Entities)); err != nil {
+		log.Warn("comment entities validation failed", logger.Err(err))
+		return nil, errors.BadRequest(err.Error())
+	}
+
+	if err := posts.ResolveMentions(db, comment.Content, commentEntityRefs(comment.Entities)); err != nil {
+		log.Warn("failed to resolve mentions", logger.Err(err))
+		return nil, err
+	}
+
+	if err := db.Create(&comment).Error; err != nil {
+		log.Error("failed to create comment in database", logger.Err(err))
+		return nil, err
+	}
+
+	if err := db.Preload("Author").Preload("Entities").First(&comment, comment.ID).Error; err != nil {
+		log.Error("failed to fetch comment from database", logger.Err(err))
+		return nil, err
+	}
+
+	log.Info("comment created successfully", slog.Uint64("comment_id", uint64(comment.ID)))
+
+	return s.mapComment(comment), nil
+}
+
+func (s *commentService) GetComment(ctx context.Context, commentID uint, userID *uint) (*CommentResponse, error) {
+	db := s.db.WithContext(ctx)
+	log := logger.FromCtx(ctx, s.logger).With(slog.Uint64("comment_id", uint64(commentID)))
+
+	log.Info("fetching comment")
+
+	var comment models.Comment
+	if err := db.Preload("Author").Preload("Entities").First(&comment, commentID).Error; err != nil {
+		if goerrors.Is(err, database.ErrRecordNotFound) {
+			log.Warn("comment not found")
+			return nil, errors.ErrNotFound
+		}
+
+		log.Error("failed to fetch comment from database", logger.Err(err))
+		return nil, err
+	}
+
+	comments := []models.Comment{comment}
+	if err := s.loadStats(db, comments, userID); err != nil {
+		log.Error("failed to load comment stats", logger.Err(err))
+		return nil, err
+	}
+
+	log.Info("comment retrieved successfully")
+
+	return s.mapComment(comments[0]), nil
+}
+
+func (s *commentService) GetComments(ctx context.Context, params ListParams, userID *uint) (*ListResponse, error) {
+	db := s.db.WithContext(ctx)
+	log := logger.FromCtx(ctx, s.logger).With(slog.Uint64("post_id", uint64(params.PostID)))
+
+	log.Info("fetching comments thread")
+	log.Debug("list parameters", slog.Any("params", params))
+
+	if err := db.Select("id").First(&models.Post{}, params.PostID).Error; err != nil {
+		if goerrors.Is(err, database.ErrRecordNotFound) {
+			log.Warn("post not found")
+			return nil, errors.ErrNotFound
+		}
+		log.Error("failed to fetch post from database", logger.Err(err))
+		return nil, err
+	}
+
+	limit := posts.NormalizeLimit(params.Limit)
+
+	query := db.Unscoped().Preload("Author").Preload("Entities").
+		Where("post_id = ?", params.PostID).
+		Where(visibleComment).
+		Order("created_at ASC, id ASC").
+		Limit(limit + 1)
+
+	if params.ParentID == 0 {
+		query = query.Where("parent_id IS NULL")
+	} else {
+		query = query.Where("parent_id = ?", params.ParentID)
+	}
+
+	if params.Cursor != "" {
+		c, err := cursor.Decode[cursor.CreatedAtID](params.Cursor)
+		if err != nil {
+			log.Warn("failed to decode cursor", logger.Err(err))
+			return nil, errors.ErrInvalidCursor
+		}
+		query = query.Where("(created_at, id) > (?, ?)", c.CreatedAt, c.ID)
+	}
+
+	var comments []models.Comment
+	if err := query.Find(&comments).Error; err != nil {
+		log.Error("failed to fetch comments from database", logger.Err(err))
+		return nil, err
+	}
+
+	listResponse := &ListResponse{}
+	if len(comments) > limit {
+		comments = comments[:limit]
+
+		last := comments[len(comments)-1]
+		nextCursor, err := cursor.Encode(cursor.CreatedAtID{CreatedAt: last.CreatedAt, ID: last.ID})
+		if err != nil {
+			log.Error("failed to encode next cursor", logger.Err(err))
+			return nil, err
+		}
+		listResponse.NextCursor = nextCursor
+	}
+
+	if err := s.loadStats(db, comments, userID); err != nil {
+		log.Error("failed to load comment stats", logger.Err(err))
+		return nil, err
+	}
+
+	listResponse.Result = make([]*CommentResponse, len(comments))
+	for i, comment := range comments {
+		listResponse.Result[i] = s.mapComment(comment)
+	}
+
+	log.Info("comments retrieved successfully",
+		slog.Int("returned", len(comments)),
+		slog.Bool("authenticated", userID != nil),
+	)
+
+	return listResponse, nil
+}
+
+func (s *commentService) UpdateComment(ctx context.Context, userID uint, commentID uint, input UpdateCommentInput) (*CommentResponse, error) {
+	db := s.db.WithContext(ctx)
+	log := logger.WithUserID(logger.FromCtx(ctx, s.logger), userID).With(slog.Uint64("comment_id", uint64(commentID)))
+
+	log.Info("updating comment")
+	log.Debug("update input data", slog.Any("input", input))
+
+	inputEntities, err := posts.ConvertEntityOffsets(input.Content, input.Entities, input.OffsetUnit)
+	if err != nil {
+		log.Warn("entity offset conversion failed", logger.Err(err))
+		return nil, errors.BadRequest(err.Error())
+	}
+
+	entities := MapInputsToCommentEntity(inputEntities)
+	if err := posts.ValidateEntities(input.Content, commentEntities(entities)); err != nil {
+		log.Warn("comment entities validation failed", logger.Err(err))
+		return nil, errors.BadRequest(err.Error())
+	}
+
+	if err := posts.ResolveMentions(db, input.Content, commentEntityRefs(entities)); err != nil {
+		log.Warn("failed to resolve mentions", logger.Err(err))
+		return nil, err
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var comment models.Comment
+		if err := tx.First(&comment, commentID).Error; err != nil {
+			if goerrors.Is(err, database.ErrRecordNotFound) {
+				log.Warn("comment not found")
+				return errors.ErrNotFound
+			}
+			log.Error("failed to fetch comment for update", logger.Err(err))
+			return err
+		}
+
+		if comment.AuthorID != userID {
+			log.Warn("unauthorized update attempt",
+				slog.Uint64("comment_author_id", uint64(comment.AuthorID)),
+				slog.Uint64("request_user_id", uint64(userID)),
+			)
+			return errors.ErrForbidden
+		}
+
+		editedAt := time.Now().UTC()
+		if err := tx.Model(&comment).Updates(models.Comment{
+			Content:    input.Content,
+			EditedAt:   &editedAt,
+			OffsetUnit: posts.OffsetUnit,
+		}).Error; err != nil {
+			log.Error("failed to update comment fields", logger.Err(err))
+			return err
+		}
+
+		if err := tx.Unscoped().Model(&comment).Association("Entities").Unscoped().Clear(); err != nil {
+			log.Error("failed to clear comment entities", logger.Err(err))
+			return err
+		}
+
+		if len(entities) > 0 {
+			for i := range entities {
+				entities[i].CommentID = comment.ID
+			}
+
+			if err := tx.Create(&entities).Error; err != nil {
+				log.Error("failed to create new entities", logger.Err(err))
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		log.Error("transaction failed during comment update", logger.Err(err))
+		return nil, err
+	}
+
+	var updated models.Comment
+	if err = db.Preload("Author").Preload("Entities").First(&updated, commentID).Error; err != nil {
+		log.Error("failed to load updated comment", logger.Err(err))
+		return nil, err
+	}
+
+	comments := []models.Comment{updated}
+	if err := s.loadStats(db, comments, &userID); err != nil {
+		log.Error("failed to load comment stats", logger.Err(err))
+		return nil, err
+	}
+
+	log.Info("comment updated successfully")
+
+	return s.mapComment(comments[0]), nil
+}
+
+func (s *commentService) DeleteComment(ctx context.Context, userID uint, commentID uint) error {
+	db := s.db.WithContext(ctx)
+	log := logger.WithUserID(logger.FromCtx(ctx, s.logger), userID).With(slog.Uint64("comment_id", uint64(commentID)))
+
+	log.Info("deleting comment")
+
+	var comment models.Comment
+	if err := db.First(&comment, commentID).Error; err != nil {
+		if goerrors.Is(err, database.ErrRecordNotFound) {
+			log.Warn("comment not found")
+			return errors.ErrNotFound
+		}
+		log.Error("failed to fetch comment for deletion", logger.Err(err))
+		return err
+	}
+
+	if comment.AuthorID != userID {
+		log.Warn("unauthorized deletion attempt",
+			slog.Uint64("comment_author_id", uint64(comment.AuthorID)),
+			slog.Uint64("request_user_id", uint64(userID)),
+		)
+		return errors.ErrForbidden
+	}
+
+	if err := db.Delete(&comment).Error; err != nil {
+		log.Error("failed to delete comment", logger.Err(err))
+		return err
+	}
+
+	log.Info("comment deleted successfully")
+
+	return nil
+}
+
+// loadStats fills replies counts and reactions of the given comments in place.
+func (s *commentService) loadStats(db *gorm.DB, comments []models.Comment, userID *uint) error {
+	if len(comments) == 0 {
+		return nil
+	}
+
+	commentIDs := make([]uint, len(comments))
+	for i, c := range comments {
+		commentIDs[i] = c.ID
+	}
+
+	repliesCounts, err := GetRepliesCounts(db, commentIDs)
+	if err != nil {
+		return err
+	}
+
+	aggReact, err := reactions.GetReactionsAggregate(db, reactions.TargetComment, commentIDs)
+	if err != nil {
+		return err
+	}
+
+	var userReact map[uint][]models.UserReaction
+	if userID != nil && len(aggReact) > 0 {
+		userReact, err = reactions.GetUserReactions(db, reactions.TargetComment, commentIDs, *userID)
+		if err != nil {
+			return err
+		}
+	}
+
+	for i := range comments {
+		curr := &comments[i]
+		curr.RepliesCount = repliesCounts[curr.ID]
+		curr.Reactions = aggReact[curr.ID]
+		curr.UserReactions = userReact[curr.ID]
+	}
+	return nil
+}
+
+// mapComment maps a comment along with the avatar URLs of its author.
+func (s *commentService) mapComment(comment models.Comment) *CommentResponse {
+	res := MapCommentToResponse(comment)
+	if res != nil {
+		users.SetAvatarURLs(s.urls.URL, res.Author)
+	}
+	return res
+}
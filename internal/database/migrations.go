@@ -2,15 +2,255 @@ package database
 
 import (
 	"blog-api/internal/models"
+	"blog-api/pkg/textoffset"
+	"net/url"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
 )
 
+const migrationBatchSize = 500
+
 func (d *DB) RunMigrations() error {
 	db := d.Get()
-	return db.AutoMigrate(
+
+	// media objects became shared between rows with the same content, and
+	// users may leave several reactions on the same target
+	for _, index := range []string{"idx_media_key", "idx_media_variants_key", "idx_user_target"} {
+		if err := dropUniqueIndex(db, index); err != nil {
+			return err
+		}
+	}
+
+	if err := db.AutoMigrate(
 		&models.User{},
 		&models.Post{},
 		&models.PostEntity{},
 		&models.ReactionType{},
 		&models.Reaction{},
-	)
+		&models.ReactionCount{},
+		&models.Comment{},
+		&models.CommentEntity{},
+		&models.Follow{},
+		&models.BookmarkCollection{},
+		&models.Bookmark{},
+		&models.Media{},
+		&models.MediaVariant{},
+		&models.StoredObject{},
+		&models.Upload{},
+		&models.AvatarHistory{},
+		&models.PostAttachment{},
+		&models.SecurityEvent{},
+	); err != nil {
+		return err
+	}
+
+	if err := migrateEntityOffsets(db, "posts", "post_entities", "post_id"); err != nil {
+		return err
+	}
+	if err := migrateEntityOffsets(db, "comments", "comment_entities", "comment_id"); err != nil {
+		return err
+	}
+	if err := migrateAvatarKeys(db); err != nil {
+		return err
+	}
+	if err := migrateStoredObjects(db); err != nil {
+		return err
+	}
+	if err := migrateReactionCounts(db); err != nil {
+		return err
+	}
+	return seedReactionTypes(db)
+}
+
+type legacyText struct {
+	ID      uint
+	Content string
+}
+
+type legacyEntity struct {
+	ID     uint
+	Offset int
+	Length int
+}
+
+// migrateEntityOffsets converts entity offsets of rows written before offsets
+// were measured in UTF-16 code units. Those rows have no offset_unit and store
+// byte offsets; ranges that split a character are widened to whole
+// characters. Soft-deleted rows are converted too, so they render correctly
+// if restored. The migration is idempotent and runs in batches.
+func migrateEntityOffsets(db *gorm.DB, table, entityTable, foreignKey string) error {
+	for {
+		var rows []legacyText
+		if err := db.Table(table).
+			Select("id, content").
+			Where("offset_unit IS NULL OR offset_unit = ''").
+			Order("id").
+			Limit(migrationBatchSize).
+			Find(&rows).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			for _, row := range rows {
+				var entities []legacyEntity
+				if err := tx.Table(entityTable).
+					Select("id, \"offset\", length").
+					Where(foreignKey+" = ?", row.ID).
+					Find(&entities).Error; err != nil {
+					return err
+				}
+
+				for _, e := range entities {
+					offset, length := textoffset.Snap(row.Content, e.Offset, e.Length, textoffset.Byte, textoffset.UTF16)
+					if offset == e.Offset && length == e.Length {
+						continue
+					}
+					if err := tx.Table(entityTable).Where("id = ?", e.ID).
+						Updates(map[string]any{"offset": offset, "length": length}).Error; err != nil {
+						return err
+					}
+				}
+
+				if err := tx.Table(table).Where("id = ?", row.ID).
+					Update("offset_unit", string(textoffset.UTF16)).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+}
+
+type legacyAvatar struct {
+	ID     uint
+	Avatar string
+}
+
+// migrateAvatarKeys replaces avatar URLs, stored before users.avatar held
+// object keys, with the keys. The URLs had the form
+// http://<endpoint>/<bucket>/<key>?ts=<n>; anything else is dropped, leaving
+// the user without an avatar rather than with a broken one.
+func migrateAvatarKeys(db *gorm.DB) error {
+	for {
+		var rows []legacyAvatar
+		if err := db.Table("users").
+			Select("id, avatar").
+			Where("avatar LIKE ?", "%://%").
+			Order("id").
+			Limit(migrationBatchSize).
+			Find(&rows).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			for _, row := range rows {
+				updates := map[string]any{"avatar": nil, "avatar_variants": ""}
+				if key := avatarKeyFromURL(row.Avatar); key != "" {
+					updates = map[string]any{"avatar": key}
+				}
+				if err := tx.Table("users").Where("id = ?", row.ID).Updates(updates).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func avatarKeyFromURL(avatar string) string {
+	u, err := url.Parse(avatar)
+	if err != nil {
+		return ""
+	}
+
+	// drop the bucket
+	_, key, ok := strings.Cut(strings.TrimPrefix(u.Path, "/"), "/")
+	if !ok {
+		return ""
+	}
+	return key
+}
+
+// dropUniqueIndex drops an index an earlier schema made unique. AutoMigrate
+// creates it again as a plain one if the models still declare it.
+func dropUniqueIndex(db *gorm.DB, index string) error {
+	var unique bool
+	if err := db.Raw(`
+		SELECT i.indisunique
+		FROM pg_index i
+		JOIN pg_class c ON c.oid = i.indexrelid
+		WHERE c.relname = ?`, index).
+		Scan(&unique).Error; err != nil {
+		return err
+	}
+	if !unique {
+		return nil
+	}
+	return db.Exec("DROP INDEX " + index).Error
+}
+
+// migrateStoredObjects creates the reference counts of media stored before
+// objects were shared, one reference per media row.
+func migrateStoredObjects(db *gorm.DB) error {
+	return db.Exec(`
+		INSERT INTO stored_objects (key, ref_count, variants, created_at)
+		SELECT m.key, COUNT(*), COALESCE((
+			SELECT string_agg(DISTINCT v.size::text, ',')
+			FROM media_variants v
+			WHERE v.media_id = MIN(m.id)
+		), ''), NOW()
+		FROM media m
+		WHERE NOT EXISTS (SELECT 1 FROM stored_objects o WHERE o.key = m.key)
+		GROUP BY m.key`).Error
+}
+
+// migrateReactionCounts fills the reaction counters the first time they are
+// created. Counters that drift later are fixed by cmd/reconcile-reactions.
+func migrateReactionCounts(db *gorm.DB) error {
+	return db.Exec(`
+		INSERT INTO reaction_counts (target_type, target_id, reaction_type_id, count)
+		SELECT target_type, target_id, reaction_type_id, COUNT(*)
+		FROM reactions
+		WHERE NOT EXISTS (SELECT 1 FROM reaction_counts)
+		GROUP BY target_type, target_id, reaction_type_id`).Error
+}
+
+// defaultReactionTypes are created on a database without reaction types.
+// From then on they are managed through the admin endpoints.
+var defaultReactionTypes = []models.ReactionType{
+	{Name: "like", Icon: "👍", IsActive: true, SortOrder: 0},
+	{Name: "love", Icon: "❤️", IsActive: true, SortOrder: 1},
+	{Name: "laugh", Icon: "😂", IsActive: true, SortOrder: 2},
+	{Name: "wow", Icon: "😮", IsActive: true, SortOrder: 3},
+	{Name: "sad", Icon: "😢", IsActive: true, SortOrder: 4},
+	{Name: "angry", Icon: "😡", IsActive: true, SortOrder: 5},
+}
+
+// seedReactionTypes creates the default reaction types, unless there are
+// reaction types already; renamed or deactivated defaults are not restored.
+func seedReactionTypes(db *gorm.DB) error {
+	var count int64
+	if err := db.Model(&models.ReactionType{}).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	types := slices.Clone(defaultReactionTypes)
+	return db.Create(&types).Error
 }
@@ -10,6 +10,23 @@ var (
 	ErrForbidden             = New(403, "forbidden")
 	ErrInvalidFile           = New(400, "invalid file")
 	ErrInvalidQuery          = New(400, "invalid query parameters")
+	ErrInvalidCursor         = New(400, "invalid cursor")
+	ErrCommentTooDeep        = New(400, "comment thread is too deep")
+	ErrCannotFollowSelf      = New(400, "cannot follow yourself")
+	ErrCannotReactToSelf     = New(400, "cannot react to yourself")
+
+	ErrUploadExpired            = New(410, "upload has expired")
+	ErrUploadNotReceived        = New(400, "file has not been uploaded yet")
+	ErrUploadInProgress         = New(409, "upload is already being confirmed")
+	ErrDirectUploadsUnsupported = New(501, "direct uploads are not supported by the storage")
+	ErrQuotaExceeded            = NewCoded(403, "quota_exceeded", "storage quota exceeded")
+	ErrMalwareDetected          = NewCoded(422, "malware_detected", "file was rejected by the malware scanner")
+	ErrScanUnavailable          = New(503, "file could not be scanned, try again later")
+
+	ErrCollectionAlreadyExists = New(409, "collection with this name already exists")
+
+	ErrReactionTypeAlreadyExists = New(409, "reaction type with this name already exists")
+	ErrReactionLimitReached      = NewCoded(409, "reaction_limit_reached", "reaction limit reached, remove a reaction first")
 
 	ErrIncorrectOldPassword = New(400, "incorrect old password")
 	ErrNewPasswordSameAsOld = New(400, "new password cannot be the same as old password")
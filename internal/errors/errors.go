@@ -10,12 +10,30 @@ import (
 type Error struct {
 	Code int    `json:"code"`
 	Msg  string `json:"msg"`
+
+	// Reason is a stable identifier clients can recognise the error by, and
+	// Details describes the particular occurrence.
+	Reason  string `json:"reason,omitempty"`
+	Details any    `json:"details,omitempty"`
 }
 
 func (e Error) Error() string {
 	return e.Msg
 }
 
+// Is matches errors derived from the same coded error by WithDetails.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && e.Reason != "" && e.Reason == t.Reason
+}
+
+// WithDetails returns a copy of the error carrying details.
+func (e *Error) WithDetails(details any) *Error {
+	err := *e
+	err.Details = details
+	return &err
+}
+
 func New(code int, msg string) *Error {
 	return &Error{
 		Code: code,
@@ -23,6 +41,14 @@ func New(code int, msg string) *Error {
 	}
 }
 
+func NewCoded(code int, reason, msg string) *Error {
+	return &Error{
+		Code:   code,
+		Msg:    msg,
+		Reason: reason,
+	}
+}
+
 func BadRequest(msg string) *Error {
 	return New(http.StatusBadRequest, msg)
 }
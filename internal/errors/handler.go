@@ -40,9 +40,15 @@ func NewErrorHandler(l *slog.Logger) fiber.ErrorHandler {
 			msg = utils.StatusMessage(fiber.StatusUnprocessableEntity)
 		}
 
-		var apiErr *Error
+		var (
+			apiErr  *Error
+			reason  string
+			details any
+		)
 		if goerrors.As(err, &apiErr) {
 			code = apiErr.Code
+			reason = apiErr.Reason
+			details = apiErr.Details
 		}
 
 		if code >= 500 {
@@ -62,8 +68,10 @@ func NewErrorHandler(l *slog.Logger) fiber.ErrorHandler {
 		}
 
 		return ctx.Status(code).JSON(response.Response[struct{}]{
-			OK:  false,
-			Msg: msg,
+			OK:      false,
+			Msg:     msg,
+			Reason:  reason,
+			Details: details,
 		})
 	}
 }
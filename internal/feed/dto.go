@@ -0,0 +1,13 @@
+package feed
+
+import "blog-api/internal/posts"
+
+type FeedParams struct {
+	Limit  int    `query:"limit" validate:"omitempty,min=1,max=100"`
+	Cursor string `query:"cursor" validate:"omitempty,max=512"`
+}
+
+type ListResponse struct {
+	NextCursor string                `json:"next_cursor,omitempty"`
+	Result     []*posts.PostResponse `json:"result"`
+}
@@ -0,0 +1,48 @@
+package feed
+
+import (
+	"blog-api/internal/errors"
+	"blog-api/internal/logger"
+	"blog-api/internal/users"
+	"blog-api/pkg/response"
+	"context"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/requestid"
+)
+
+type IFeedHandler interface {
+	GetFeed(ctx fiber.Ctx) error
+}
+
+type feedHandler struct {
+	feedService IFeedService
+}
+
+func NewFeedHandler(feedService IFeedService) IFeedHandler {
+	return &feedHandler{
+		feedService: feedService,
+	}
+}
+
+func (h *feedHandler) GetFeed(ctx fiber.Ctx) error {
+	user := users.MustGetUser(ctx)
+	requestID := requestid.FromContext(ctx)
+
+	var params FeedParams
+	if err := ctx.Bind().Query(&params); err != nil {
+		return errors.ErrInvalidQuery
+	}
+
+	data, err := h.feedService.GetFeed(
+		context.WithValue(ctx, logger.RequestIDKey, requestID),
+		user.UserID,
+		params,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(response.NewCursorPaginatedResponse(nil, data.NextCursor, "", data.Result))
+}
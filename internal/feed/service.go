@@ -0,0 +1,79 @@
+package feed
+
+import (
+	"blog-api/internal/errors"
+	"blog-api/internal/logger"
+	"blog-api/internal/posts"
+	"blog-api/pkg/cursor"
+	"context"
+	"log/slog"
+)
+
+type IFeedService interface {
+	GetFeed(ctx context.Context, userID uint, params FeedParams) (*ListResponse, error)
+}
+
+type feedService struct {
+	timeline    Timeline
+	postService posts.IPostService
+	logger      *slog.Logger
+}
+
+func NewFeedService(timeline Timeline, postService posts.IPostService, logger *slog.Logger) IFeedService {
+	return &feedService{
+		timeline:    timeline,
+		postService: postService,
+		logger:      logger,
+	}
+}
+
+func (s *feedService) GetFeed(ctx context.Context, userID uint, params FeedParams) (*ListResponse, error) {
+	log := logger.WithUserID(logger.FromCtx(ctx, s.logger), userID)
+
+	log.Info("fetching home feed")
+
+	var after *cursor.CreatedAtID
+	if params.Cursor != "" {
+		c, err := cursor.Decode[cursor.CreatedAtID](params.Cursor)
+		if err != nil {
+			log.Warn("failed to decode cursor", logger.Err(err))
+			return nil, errors.ErrInvalidCursor
+		}
+		after = &c
+	}
+
+	limit := posts.NormalizeLimit(params.Limit)
+
+	entries, err := s.timeline.Fetch(ctx, userID, after, limit+1)
+	if err != nil {
+		log.Error("failed to fetch timeline", logger.Err(err))
+		return nil, err
+	}
+
+	listResponse := &ListResponse{}
+	if len(entries) > limit {
+		entries = entries[:limit]
+
+		last := entries[len(entries)-1]
+		nextCursor, err := cursor.Encode(cursor.CreatedAtID{CreatedAt: last.CreatedAt, ID: last.PostID})
+		if err != nil {
+			log.Error("failed to encode next cursor", logger.Err(err))
+			return nil, err
+		}
+		listResponse.NextCursor = nextCursor
+	}
+
+	postIDs := make([]uint, len(entries))
+	for i, e := range entries {
+		postIDs[i] = e.PostID
+	}
+
+	listResponse.Result, err = s.postService.GetPostsByIDs(ctx, postIDs, &userID)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info("home feed retrieved successfully", slog.Int("returned", len(listResponse.Result)))
+
+	return listResponse, nil
+}
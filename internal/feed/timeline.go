@@ -0,0 +1,52 @@
+package feed
+
+import (
+	"blog-api/internal/database"
+	"blog-api/pkg/cursor"
+	"context"
+	"time"
+)
+
+// Entry is a single post reference in a user's timeline.
+type Entry struct {
+	PostID    uint
+	CreatedAt time.Time
+}
+
+// Timeline returns the newest posts a user should see, starting strictly
+// after the given cursor. The Postgres implementation computes it on read;
+// a precomputed (fan-out-on-write) timeline, e.g. a Redis sorted set per
+// user, only has to honour the same ordering to replace it.
+type Timeline interface {
+	Fetch(ctx context.Context, userID uint, after *cursor.CreatedAtID, limit int) ([]Entry, error)
+}
+
+type postgresTimeline struct {
+	db *database.DB
+}
+
+func NewPostgresTimeline(db *database.DB) Timeline {
+	return &postgresTimeline{
+		db: db,
+	}
+}
+
+func (t *postgresTimeline) Fetch(ctx context.Context, userID uint, after *cursor.CreatedAtID, limit int) ([]Entry, error) {
+	query := t.db.WithContext(ctx).
+		Table("posts").
+		Select("posts.id AS post_id, posts.created_at AS created_at").
+		Joins("JOIN follows f ON f.followee_id = posts.author_id").
+		Where("f.follower_id = ? AND posts.deleted_at IS NULL", userID).
+		Order("posts.created_at DESC, posts.id DESC").
+		Limit(limit)
+
+	if after != nil {
+		query = query.Where("(posts.created_at, posts.id) < (?, ?)", after.CreatedAt, after.ID)
+	}
+
+	var entries []Entry
+	if err := query.Scan(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
@@ -0,0 +1,18 @@
+package follows
+
+import "blog-api/internal/users"
+
+type ListParams struct {
+	Limit  int    `query:"limit" validate:"omitempty,min=1,max=100"`
+	Cursor string `query:"cursor" validate:"omitempty,max=512"`
+}
+
+type FollowResponse struct {
+	UserID      uint `json:"user_id"`
+	IsFollowing bool `json:"is_following"`
+}
+
+type ListResponse struct {
+	NextCursor string                `json:"next_cursor,omitempty"`
+	Result     []*users.UserResponse `json:"result"`
+}
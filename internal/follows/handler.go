@@ -0,0 +1,111 @@
+package follows
+
+import (
+	"blog-api/internal/errors"
+	"blog-api/internal/logger"
+	"blog-api/internal/users"
+	"blog-api/pkg/response"
+	"context"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/requestid"
+)
+
+type IFollowHandler interface {
+	Follow(ctx fiber.Ctx) error
+	Unfollow(ctx fiber.Ctx) error
+	GetFollowers(ctx fiber.Ctx) error
+	GetFollowing(ctx fiber.Ctx) error
+}
+
+type followHandler struct {
+	followService IFollowService
+}
+
+func NewFollowHandler(followService IFollowService) IFollowHandler {
+	return &followHandler{
+		followService: followService,
+	}
+}
+
+func (h *followHandler) Follow(ctx fiber.Ctx) error {
+	user := users.MustGetUser(ctx)
+	targetID := fiber.Params[uint](ctx, "id")
+
+	requestID := requestid.FromContext(ctx)
+
+	res, err := h.followService.Follow(
+		context.WithValue(ctx, logger.RequestIDKey, requestID),
+		user.UserID,
+		targetID,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(response.NewResponse(res))
+}
+
+func (h *followHandler) Unfollow(ctx fiber.Ctx) error {
+	user := users.MustGetUser(ctx)
+	targetID := fiber.Params[uint](ctx, "id")
+
+	requestID := requestid.FromContext(ctx)
+
+	res, err := h.followService.Unfollow(
+		context.WithValue(ctx, logger.RequestIDKey, requestID),
+		user.UserID,
+		targetID,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(response.NewResponse(res))
+}
+
+func (h *followHandler) GetFollowers(ctx fiber.Ctx) error {
+	targetID := fiber.Params[uint](ctx, "id")
+	requestID := requestid.FromContext(ctx)
+
+	var params ListParams
+	if err := ctx.Bind().Query(&params); err != nil {
+		return errors.ErrInvalidQuery
+	}
+
+	data, err := h.followService.GetFollowers(
+		context.WithValue(ctx, logger.RequestIDKey, requestID),
+		targetID,
+		params,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(response.NewCursorPaginatedResponse(nil, data.NextCursor, "", data.Result))
+}
+
+func (h *followHandler) GetFollowing(ctx fiber.Ctx) error {
+	targetID := fiber.Params[uint](ctx, "id")
+	requestID := requestid.FromContext(ctx)
+
+	var params ListParams
+	if err := ctx.Bind().Query(&params); err != nil {
+		return errors.ErrInvalidQuery
+	}
+
+	data, err := h.followService.GetFollowing(
+		context.WithValue(ctx, logger.RequestIDKey, requestID),
+		targetID,
+		params,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(response.NewCursorPaginatedResponse(nil, data.NextCursor, "", data.Result))
+}
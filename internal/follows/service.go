@@ -0,0 +1,177 @@
+package follows
+
+import (
+	"blog-api/internal/database"
+	"blog-api/internal/errors"
+	"blog-api/internal/logger"
+	"blog-api/internal/models"
+	"blog-api/internal/posts"
+	"blog-api/internal/storage"
+	"blog-api/internal/users"
+	"blog-api/pkg/cursor"
+	"context"
+	goerrors "errors"
+	"log/slog"
+
+	"gorm.io/gorm/clause"
+)
+
+type IFollowService interface {
+	Follow(ctx context.Context, userID uint, targetID uint) (*FollowResponse, error)
+	Unfollow(ctx context.Context, userID uint, targetID uint) (*FollowResponse, error)
+	GetFollowers(ctx context.Context, targetID uint, params ListParams) (*ListResponse, error)
+	GetFollowing(ctx context.Context, targetID uint, params ListParams) (*ListResponse, error)
+}
+
+type followService struct {
+	db     *database.DB
+	urls   storage.URLResolver
+	logger *slog.Logger
+}
+
+func NewFollowService(db *database.DB, urls storage.URLResolver, logger *slog.Logger) IFollowService {
+	return &followService{
+		db:     db,
+		urls:   urls,
+		logger: logger,
+	}
+}
+
+func (s *followService) Follow(ctx context.Context, userID uint, targetID uint) (*FollowResponse, error) {
+	db := s.db.WithContext(ctx)
+	log := logger.WithUserID(logger.FromCtx(ctx, s.logger), userID).With(slog.Uint64("target_id", uint64(targetID)))
+
+	log.Info("following user")
+
+	if userID == targetID {
+		log.Warn("attempt to follow self")
+		return nil, errors.ErrCannotFollowSelf
+	}
+
+	if err := s.ensureUserExists(ctx, targetID); err != nil {
+		return nil, err
+	}
+
+	follow := models.Follow{
+		FollowerID: userID,
+		FolloweeID: targetID,
+	}
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&follow).Error; err != nil {
+		log.Error("failed to create follow", logger.Err(err))
+		return nil, err
+	}
+
+	log.Info("user followed successfully")
+
+	return &FollowResponse{
+		UserID:      targetID,
+		IsFollowing: true,
+	}, nil
+}
+
+func (s *followService) Unfollow(ctx context.Context, userID uint, targetID uint) (*FollowResponse, error) {
+	db := s.db.WithContext(ctx)
+	log := logger.WithUserID(logger.FromCtx(ctx, s.logger), userID).With(slog.Uint64("target_id", uint64(targetID)))
+
+	log.Info("unfollowing user")
+
+	if err := db.Where("follower_id = ? AND followee_id = ?", userID, targetID).Delete(&models.Follow{}).Error; err != nil {
+		log.Error("failed to delete follow", logger.Err(err))
+		return nil, err
+	}
+
+	log.Info("user unfollowed successfully")
+
+	return &FollowResponse{
+		UserID:      targetID,
+		IsFollowing: false,
+	}, nil
+}
+
+func (s *followService) GetFollowers(ctx context.Context, targetID uint, params ListParams) (*ListResponse, error) {
+	return s.list(ctx, targetID, params, "followee_id", "Follower")
+}
+
+func (s *followService) GetFollowing(ctx context.Context, targetID uint, params ListParams) (*ListResponse, error) {
+	return s.list(ctx, targetID, params, "follower_id", "Followee")
+}
+
+// list returns the users on the other side of targetID's follow relations.
+// The column and relation names are constants chosen by the callers above.
+func (s *followService) list(ctx context.Context, targetID uint, params ListParams, ownColumn, relation string) (*ListResponse, error) {
+	db := s.db.WithContext(ctx)
+	log := logger.FromCtx(ctx, s.logger).With(
+		slog.Uint64("target_id", uint64(targetID)),
+		slog.String("relation", relation),
+	)
+
+	log.Info("fetching follow list")
+
+	if err := s.ensureUserExists(ctx, targetID); err != nil {
+		return nil, err
+	}
+
+	limit := posts.NormalizeLimit(params.Limit)
+
+	query := db.Model(&models.Follow{}).
+		Where(ownColumn+" = ?", targetID).
+		Order("created_at DESC, id DESC").
+		Limit(limit + 1)
+
+	if params.Cursor != "" {
+		c, err := cursor.Decode[cursor.CreatedAtID](params.Cursor)
+		if err != nil {
+			log.Warn("failed to decode cursor", logger.Err(err))
+			return nil, errors.ErrInvalidCursor
+		}
+		query = query.Where("(created_at, id) < (?, ?)", c.CreatedAt, c.ID)
+	}
+
+	var follows []models.Follow
+	if err := query.Preload(relation).Find(&follows).Error; err != nil {
+		log.Error("failed to fetch follows from database", logger.Err(err))
+		return nil, err
+	}
+
+	listResponse := &ListResponse{}
+	if len(follows) > limit {
+		follows = follows[:limit]
+
+		last := follows[len(follows)-1]
+		nextCursor, err := cursor.Encode(cursor.CreatedAtID{CreatedAt: last.CreatedAt, ID: last.ID})
+		if err != nil {
+			log.Error("failed to encode next cursor", logger.Err(err))
+			return nil, err
+		}
+		listResponse.NextCursor = nextCursor
+	}
+
+	listResponse.Result = make([]*users.UserResponse, len(follows))
+	for i, f := range follows {
+		if relation == "Follower" {
+			listResponse.Result[i] = users.MapUserToResponse(f.Follower)
+		} else {
+			listResponse.Result[i] = users.MapUserToResponse(f.Followee)
+		}
+	}
+	users.SetAvatarURLs(s.urls.URL, listResponse.Result...)
+
+	log.Info("follow list retrieved successfully", slog.Int("returned", len(follows)))
+
+	return listResponse, nil
+}
+
+func (s *followService) ensureUserExists(ctx context.Context, userID uint) error {
+	db := s.db.WithContext(ctx)
+	log := logger.FromCtx(ctx, s.logger).With(slog.Uint64("target_id", uint64(userID)))
+
+	if err := db.Select("id").First(&models.User{}, userID).Error; err != nil {
+		if goerrors.Is(err, database.ErrRecordNotFound) {
+			log.Warn("user not found")
+			return errors.ErrNotFound
+		}
+		log.Error("database query failed", logger.Err(err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"blog-api/internal/errors"
+	"blog-api/internal/logger"
+	"blog-api/internal/users"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/requestid"
+)
+
+// AdminMiddleware only lets admins through. It must run after AuthMiddleware.
+func (m *Manager) AdminMiddleware() fiber.Handler {
+	log := m.log.With(slog.String("component", "middleware/admin"))
+
+	return func(ctx fiber.Ctx) error {
+		user := users.GetUser(ctx)
+		if user == nil || !user.IsAdmin() {
+			reqLog := log.With(slog.String(string(logger.RequestIDKey), requestid.FromContext(ctx)))
+			if user != nil {
+				reqLog = logger.WithUserID(reqLog, user.UserID)
+			}
+			reqLog.Warn("admin access denied")
+			return errors.ErrForbidden
+		}
+		return ctx.Next()
+	}
+}
@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"blog-api/internal/errors"
+	"blog-api/internal/storage"
+	"log/slog"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// SignedURLMiddleware guards files served from the local storage under
+// prefix, letting through only URLs signed by the store.
+func (m *Manager) SignedURLMiddleware(store *storage.LocalStore, prefix string) fiber.Handler {
+	log := m.log.With(slog.String("component", "middleware/signed_url"))
+
+	return func(ctx fiber.Ctx) error {
+		key := strings.TrimPrefix(ctx.Path(), strings.TrimSuffix(prefix, "/")+"/")
+		expires := fiber.Query[int64](ctx, "expires")
+		signature := fiber.Query[string](ctx, "signature")
+
+		if !store.Verify(key, expires, signature) {
+			log.Debug("invalid media url signature", slog.String("key", key))
+			return errors.ErrForbidden
+		}
+		return ctx.Next()
+	}
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// AvatarHistory is a replaced avatar of a user, kept so that it can be made
+// the avatar again. CreatedAt is when it was replaced.
+type AvatarHistory struct {
+	ID     uint   `gorm:"primaryKey"`
+	UserID uint   `gorm:"index;not null"`
+	Key    string `gorm:"size:255;not null;uniqueIndex"`
+	// Variants lists the comma separated sizes generated for Key
+	Variants      string `gorm:"size:100"`
+	BlurHash      string `gorm:"size:64"`
+	DominantColor string `gorm:"size:7"`
+	CreatedAt     time.Time
+
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+type BookmarkCollection struct {
+	ID     uint   `gorm:"primaryKey"`
+	UserID uint   `gorm:"not null;uniqueIndex:idx_user_collection_name"`
+	Name   string `gorm:"size:100;not null;uniqueIndex:idx_user_collection_name"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+
+	BookmarksCount int64 `gorm:"->;-:migration"`
+}
+
+type Bookmark struct {
+	ID           uint  `gorm:"primaryKey"`
+	UserID       uint  `gorm:"not null;uniqueIndex:idx_user_bookmark"`
+	PostID       uint  `gorm:"not null;uniqueIndex:idx_user_bookmark"`
+	CollectionID *uint `gorm:"index"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	User       User                `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	Post       Post                `gorm:"foreignKey:PostID;constraint:OnDelete:CASCADE"`
+	Collection *BookmarkCollection `gorm:"foreignKey:CollectionID;constraint:OnDelete:SET NULL"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Comment struct {
+	gorm.Model
+	PostID   uint   `gorm:"index;not null"`
+	AuthorID uint   `gorm:"index;not null"`
+	ParentID *uint  `gorm:"index"`
+	Depth    int    `gorm:"not null;default:0"`
+	Content  string `gorm:"type:text;not null"`
+	EditedAt *time.Time
+	// OffsetUnit has the same meaning as Post.OffsetUnit.
+	OffsetUnit string `gorm:"size:10"`
+
+	Author   User            `gorm:"foreignKey:AuthorID"`
+	Post     Post            `gorm:"foreignKey:PostID;constraint:OnDelete:CASCADE"`
+	Parent   *Comment        `gorm:"foreignKey:ParentID;constraint:OnDelete:CASCADE"`
+	Entities []CommentEntity `gorm:"foreignKey:CommentID;constraint:OnDelete:CASCADE"`
+
+	RepliesCount  int64          `gorm:"-"`
+	UserReactions []UserReaction `gorm:"-"`
+	Reactions     []ReactionStat `gorm:"-"`
+}
+
+type CommentEntity struct {
+	gorm.Model
+	CommentID uint `gorm:"index;not null"`
+	Entity
+
+	Comment Comment `gorm:"foreignKey:CommentID"`
+}
+
+func (e *CommentEntity) TableName() string {
+	return "comment_entities"
+}
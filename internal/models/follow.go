@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+type Follow struct {
+	ID         uint `gorm:"primaryKey"`
+	FollowerID uint `gorm:"not null;uniqueIndex:idx_follower_followee"`
+	FolloweeID uint `gorm:"not null;uniqueIndex:idx_follower_followee;index"`
+
+	CreatedAt time.Time
+
+	Follower User `gorm:"foreignKey:FollowerID;constraint:OnDelete:CASCADE"`
+	Followee User `gorm:"foreignKey:FolloweeID;constraint:OnDelete:CASCADE"`
+}
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Media is an uploaded file stored in the object storage under Key. It
+// belongs to the user who uploaded it until it is attached somewhere.
+// Identical uploads share the object, see StoredObject.
+type Media struct {
+	ID          uint   `gorm:"primaryKey"`
+	UserID      uint   `gorm:"index;not null"`
+	Key         string `gorm:"size:255;not null;index"`
+	ContentType string `gorm:"size:50;not null"`
+	Size        int64  `gorm:"not null"`
+	Width       int    `gorm:"not null"`
+	Height      int    `gorm:"not null"`
+	// placeholders shown while the image loads
+	BlurHash      string `gorm:"size:64"`
+	DominantColor string `gorm:"size:7"`
+	CreatedAt     time.Time
+
+	User     User           `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	Variants []MediaVariant `gorm:"foreignKey:MediaID;constraint:OnDelete:CASCADE"`
+}
+
+func (m *Media) TableName() string {
+	return "media"
+}
+
+// MediaVariant is a resized copy of a media stored next to the original.
+type MediaVariant struct {
+	ID          uint   `gorm:"primaryKey"`
+	MediaID     uint   `gorm:"index;not null"`
+	Size        int    `gorm:"not null"` // bounding box, see VariantKey
+	Key         string `gorm:"size:255;not null;index"`
+	ContentType string `gorm:"size:50;not null"`
+	Bytes       int64  `gorm:"not null;default:0"`
+	Width       int    `gorm:"not null"`
+	Height      int    `gorm:"not null"`
+}
+
+// VariantKey is the object key of the size variant of the object stored
+// under key.
+func VariantKey(key string, size int) string {
+	return fmt.Sprintf("%s_%d.jpg", key, size)
+}
+
+// StoredObject is a content-addressed object in the storage, shared by all
+// media with the same content. Its key is derived from the SHA-256 of the
+// content, and it is removed from the storage with its variants once
+// RefCount drops to zero.
+type StoredObject struct {
+	Key      string `gorm:"primaryKey;size:255"`
+	RefCount int    `gorm:"not null"`
+	// Variants lists the comma separated sizes stored next to the object
+	Variants  string `gorm:"size:100"`
+	CreatedAt time.Time
+	// ReleasedAt is when a reference was last dropped
+	ReleasedAt *time.Time
+}
+
+type PostAttachment struct {
+	ID       uint   `gorm:"primaryKey"`
+	PostID   uint   `gorm:"index;not null"`
+	MediaID  uint   `gorm:"uniqueIndex;not null"`
+	Position int    `gorm:"not null"`
+	AltText  string `gorm:"size:1000"`
+	Caption  string `gorm:"size:2000"`
+
+	Post  Post  `gorm:"foreignKey:PostID;constraint:OnDelete:CASCADE"`
+	Media Media `gorm:"foreignKey:MediaID;constraint:OnDelete:CASCADE"`
+}
@@ -9,10 +9,19 @@ type Post struct {
 	Title    string `gorm:"size:255;not null"`
 	Content  string `gorm:"type:text;not null"`
 	AuthorID uint   `gorm:"index;not null"`
+	// OffsetUnit is the unit entity offsets are measured in. Rows written
+	// before offsets moved to UTF-16 code units have it empty until
+	// RunMigrations converts them.
+	OffsetUnit string `gorm:"size:10"`
 
 	Author   User         `gorm:"foreignKey:AuthorID"`
 	Entities []PostEntity `gorm:"foreignKey:PostID;constraint:OnDelete:CASCADE"`
+	// Attachments are ordered by Position when preloaded through posts.PreloadScope
+	Attachments []PostAttachment `gorm:"foreignKey:PostID"`
 
-	UserReaction *UserReaction  `gorm:"-"`
-	Reactions    []ReactionStat `gorm:"-"`
+	UserReactions []UserReaction `gorm:"-"`
+	Reactions     []ReactionStat `gorm:"-"`
+	ReactionCount int64          `gorm:"->;-:migration"` // filled only when sorting by reactions
+	CommentsCount int64          `gorm:"-"`
+	IsBookmarked  *bool          `gorm:"-"`
 }
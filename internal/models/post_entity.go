@@ -2,13 +2,37 @@ package models
 
 import "gorm.io/gorm"
 
+const (
+	EntityBold          = "bold"
+	EntityItalic        = "italic"
+	EntityUnderline     = "underline"
+	EntityStrikethrough = "strikethrough"
+	EntitySpoiler       = "spoiler"
+	EntityLink          = "link"
+	EntityCode          = "code"
+	EntityPre           = "pre"
+	EntityBlockquote    = "blockquote"
+	EntityMention       = "mention"
+	EntityHashtag       = "hashtag"
+)
+
+// Entity is a formatting span over a text field. It is shared by every
+// model that stores formatted text. Offset and Length are measured in the
+// unit recorded on the owning row, which is UTF-16 code units for all rows
+// written or migrated by the current code.
+type Entity struct {
+	Offset   int     `gorm:"not null"`
+	Length   int     `gorm:"not null"`
+	Type     string  `gorm:"size:50;not null"`
+	URL      *string `gorm:"size:500"` // link only
+	Language *string `gorm:"size:30"`  // pre only
+	UserID   *uint   `gorm:"index"`    // mention only
+}
+
 type PostEntity struct {
 	gorm.Model
-	PostID uint    `gorm:"index;not null"`
-	Offset int     `gorm:"not null"`
-	Length int     `gorm:"not null"`
-	Type   string  `gorm:"size:50;not null"`
-	URL    *string `gorm:"size:500"`
+	PostID uint `gorm:"index;not null"`
+	Entity
 
 	Post Post `gorm:"foreignKey:PostID"`
 }
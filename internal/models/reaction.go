@@ -2,21 +2,33 @@ package models
 
 import "time"
 
+// Reaction is one reaction type a user left on a target. How many types a
+// user may leave on the same target depends on the target type.
 type Reaction struct {
 	ID     uint `gorm:"primaryKey"`
-	UserID uint `gorm:"not null;uniqueIndex:idx_user_target"`
+	UserID uint `gorm:"not null;uniqueIndex:idx_user_target_reaction"`
 
-	TargetID       uint   `gorm:"not null;uniqueIndex:idx_user_target"`
-	TargetType     string `gorm:"size:50;not null;uniqueIndex:idx_user_target"`
-	ReactionTypeID uint   `gorm:"not null"`
+	TargetID       uint   `gorm:"not null;uniqueIndex:idx_user_target_reaction;index:idx_target_created,priority:2"`
+	TargetType     string `gorm:"size:50;not null;uniqueIndex:idx_user_target_reaction;index:idx_target_created,priority:1"`
+	ReactionTypeID uint   `gorm:"not null;uniqueIndex:idx_user_target_reaction"`
 
-	CreatedAt time.Time
+	CreatedAt time.Time `gorm:"index:idx_target_created,priority:3"`
 	UpdatedAt time.Time
 
 	User         User         `gorm:"foreignKey:UserID"`
 	ReactionType ReactionType `gorm:"foreignKey:ReactionTypeID"`
 }
 
+// ReactionCount is the number of reactions of one type on a target. It is
+// updated together with the reactions, so that reading counts needs no
+// aggregation over them.
+type ReactionCount struct {
+	TargetType     string `gorm:"primaryKey;size:50"`
+	TargetID       uint   `gorm:"primaryKey"`
+	ReactionTypeID uint   `gorm:"primaryKey"`
+	Count          int64  `gorm:"not null;default:0"`
+}
+
 type UserReaction struct {
 	TargetID uint   `json:"-"`
 	Type     string `json:"type"`
@@ -2,11 +2,14 @@ package models
 
 import "time"
 
+// ReactionType is a reaction users can pick. Deactivated types cannot be
+// picked anymore, but reactions already made with them are kept and counted.
 type ReactionType struct {
-	ID       uint   `gorm:"primaryKey"`
-	Name     string `gorm:"unique;size:50;not null"`
-	Icon     string `gorm:"size:50;not null"`
-	IsActive bool   `gorm:"not null;default:true"`
+	ID        uint   `gorm:"primaryKey"`
+	Name      string `gorm:"unique;size:50;not null"`
+	Icon      string `gorm:"size:50;not null"`
+	IsActive  bool   `gorm:"not null;default:true"`
+	SortOrder int    `gorm:"not null;default:0"`
 
 	CreatedAt time.Time
 	UpdatedAt time.Time
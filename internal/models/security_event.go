@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+const (
+	SecurityEventMalwareDetected = "malware_detected"
+)
+
+// SecurityEvent records something a user did that operators should review,
+// such as uploading an infected file.
+type SecurityEvent struct {
+	ID     uint   `gorm:"primaryKey"`
+	UserID uint   `gorm:"index;not null"`
+	Type   string `gorm:"size:50;not null;index"`
+	// Subject identifies what the event is about, e.g. the SHA-256 of a file
+	Subject   string `gorm:"size:255"`
+	Detail    string `gorm:"size:1000"`
+	CreatedAt time.Time
+
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
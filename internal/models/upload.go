@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+const (
+	UploadPurposeAvatar = "avatar"
+	UploadPurposePost   = "post"
+)
+
+// Upload is a slot for a file the client uploads straight to the object
+// storage. The file stays under Key until the upload is confirmed, and is
+// removed with the slot when that does not happen before ExpiresAt.
+type Upload struct {
+	ID          uint      `gorm:"primaryKey"`
+	UserID      uint      `gorm:"index;not null"`
+	Purpose     string    `gorm:"size:20;not null"`
+	Key         string    `gorm:"size:255;not null;uniqueIndex"`
+	ContentType string    `gorm:"size:50;not null"`
+	MaxSize     int64     `gorm:"not null"`
+	ExpiresAt   time.Time `gorm:"index;not null"`
+	// ConfirmedAt is set while a confirmation of the upload is in progress
+	ConfirmedAt *time.Time
+	CreatedAt   time.Time
+
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
@@ -7,17 +7,28 @@ import (
 	"gorm.io/gorm"
 )
 
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
 type User struct {
-	ID           uint           `gorm:"primaryKey"`
-	Username     string         `gorm:"type:string;size:50;not null;unique"`
-	Email        sql.NullString `gorm:"type:string;size:256;unique;default:null"`
-	Password     string         `gorm:"type:string;size:256;not null"`
-	Avatar       sql.NullString `gorm:"type:string;size:256;default:null"`
-	TwoFAEnabled bool           `gorm:"default:false;not null"`
-	TwoFASecret  sql.NullString `gorm:"type:string;size:256;default:null"`
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
-	DeletedAt    gorm.DeletedAt `gorm:"index"`
+	ID       uint           `gorm:"primaryKey"`
+	Username string         `gorm:"type:string;size:50;not null;unique"`
+	Email    sql.NullString `gorm:"type:string;size:256;unique;default:null"`
+	Password string         `gorm:"type:string;size:256;not null"`
+	Role     string         `gorm:"type:string;size:20;not null;default:user"`
+	// Avatar is the object key of the avatar, not its URL
+	Avatar sql.NullString `gorm:"type:string;size:256;default:null"`
+	// AvatarVariants lists the comma separated sizes generated for Avatar
+	AvatarVariants      string         `gorm:"type:string;size:100"`
+	AvatarBlurHash      string         `gorm:"type:string;size:64"`
+	AvatarDominantColor string         `gorm:"type:string;size:7"`
+	TwoFAEnabled        bool           `gorm:"default:false;not null"`
+	TwoFASecret         sql.NullString `gorm:"type:string;size:256;default:null"`
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+	DeletedAt           gorm.DeletedAt `gorm:"index"`
 
 	Posts []Post `gorm:"foreignKey:AuthorID"`
 }
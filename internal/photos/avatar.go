@@ -0,0 +1,205 @@
+package photos
+
+import (
+	"blog-api/internal/database"
+	"blog-api/internal/errors"
+	"blog-api/internal/logger"
+	"blog-api/internal/models"
+	"blog-api/internal/users"
+	"context"
+	goerrors "errors"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DeleteAvatar removes the avatar of the user. It is kept in the history
+// when that is enabled. Removing a missing avatar is not an error.
+func (s *photoService) DeleteAvatar(ctx context.Context, userID uint) error {
+	log := logger.WithUserID(logger.FromCtx(ctx, s.logger), userID)
+
+	var stale []string
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		user, err := lockAvatar(tx, userID)
+		if err != nil {
+			return err
+		}
+		if !user.Avatar.Valid {
+			return nil
+		}
+
+		if stale, err = s.retireAvatar(tx, user); err != nil {
+			return err
+		}
+
+		return tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]any{
+			"avatar":                nil,
+			"avatar_variants":       "",
+			"avatar_blur_hash":      "",
+			"avatar_dominant_color": "",
+		}).Error
+	})
+	if err != nil {
+		log.Error("failed to delete avatar", logger.Err(err))
+		return err
+	}
+
+	s.DeleteObjects(ctx, stale)
+
+	log.Info("avatar deleted")
+	return nil
+}
+
+func (s *photoService) GetAvatarHistory(ctx context.Context, userID uint) ([]*AvatarHistoryResponse, error) {
+	log := logger.WithUserID(logger.FromCtx(ctx, s.logger), userID)
+
+	var history []models.AvatarHistory
+	if err := s.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at DESC, id DESC").
+		Find(&history).Error; err != nil {
+		log.Error("failed to fetch avatar history", logger.Err(err))
+		return nil, err
+	}
+
+	return MapAvatarHistoryToResponse(history, s.MediaURL), nil
+}
+
+// RevertAvatar makes an avatar from the history the current one again. The
+// replaced avatar takes its place in the history.
+func (s *photoService) RevertAvatar(ctx context.Context, userID, historyID uint) (*UploadAvatarResponse, error) {
+	log := logger.WithUserID(logger.FromCtx(ctx, s.logger), userID).
+		With(slog.Uint64("history_id", uint64(historyID)))
+
+	var (
+		entry models.AvatarHistory
+		stale []string
+	)
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		user, err := lockAvatar(tx, userID)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Where("id = ? AND user_id = ?", historyID, userID).First(&entry).Error; err != nil {
+			if goerrors.Is(err, database.ErrRecordNotFound) {
+				return errors.ErrNotFound
+			}
+			return err
+		}
+
+		// out of the history first, so that trimming it cannot pick the entry
+		if err := tx.Delete(&entry).Error; err != nil {
+			return err
+		}
+
+		if stale, err = s.retireAvatar(tx, user); err != nil {
+			return err
+		}
+
+		return setAvatar(tx, userID, entry)
+	})
+	if err != nil {
+		if goerrors.Is(err, errors.ErrNotFound) {
+			log.Warn("avatar history entry not found")
+		} else {
+			log.Error("failed to revert avatar", logger.Err(err))
+		}
+		return nil, err
+	}
+
+	s.DeleteObjects(ctx, stale)
+
+	log.Info("avatar reverted", slog.String("key", entry.Key))
+	return s.avatarResponse(entry), nil
+}
+
+// retireAvatar takes the current avatar of the user off. It goes to the
+// history when that is enabled, pushing the oldest entries out. Objects that
+// are no longer needed are returned, to be deleted once the transaction
+// commits.
+func (s *photoService) retireAvatar(tx *gorm.DB, user models.User) ([]string, error) {
+	if !user.Avatar.Valid {
+		return nil, nil
+	}
+	if s.cfg.AvatarHistorySize == 0 {
+		return sizedKeys(user.Avatar.String, user.AvatarVariants), nil
+	}
+
+	if err := tx.Create(&models.AvatarHistory{
+		UserID:        user.ID,
+		Key:           user.Avatar.String,
+		Variants:      user.AvatarVariants,
+		BlurHash:      user.AvatarBlurHash,
+		DominantColor: user.AvatarDominantColor,
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	var trimmed []models.AvatarHistory
+	if err := tx.Where("user_id = ?", user.ID).
+		Order("created_at DESC, id DESC").
+		Offset(s.cfg.AvatarHistorySize).
+		Find(&trimmed).Error; err != nil {
+		return nil, err
+	}
+	if len(trimmed) == 0 {
+		return nil, nil
+	}
+
+	var stale []string
+	ids := make([]uint, len(trimmed))
+	for i, h := range trimmed {
+		ids[i] = h.ID
+		stale = append(stale, sizedKeys(h.Key, h.Variants)...)
+	}
+	if err := tx.Delete(&models.AvatarHistory{}, ids).Error; err != nil {
+		return nil, err
+	}
+	return stale, nil
+}
+
+func (s *photoService) avatarResponse(avatar models.AvatarHistory) *UploadAvatarResponse {
+	return &UploadAvatarResponse{
+		URL:           s.MediaURL(avatar.Key),
+		Variants:      users.MapAvatarVariants(s.MediaURL, avatar.Key, avatar.Variants),
+		BlurHash:      avatar.BlurHash,
+		DominantColor: avatar.DominantColor,
+	}
+}
+
+// lockAvatar reads the avatar columns of the user, locking the row until the
+// transaction ends.
+func lockAvatar(tx *gorm.DB, userID uint) (models.User, error) {
+	var user models.User
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id", "avatar", "avatar_variants", "avatar_blur_hash", "avatar_dominant_color").
+		First(&user, userID).Error
+	return user, err
+}
+
+// setAvatar makes avatar, in the shape the history keeps it in, the avatar
+// of the user.
+func setAvatar(tx *gorm.DB, userID uint, avatar models.AvatarHistory) error {
+	return tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]any{
+		"avatar":                avatar.Key,
+		"avatar_variants":       avatar.Variants,
+		"avatar_blur_hash":      avatar.BlurHash,
+		"avatar_dominant_color": avatar.DominantColor,
+	}).Error
+}
+
+// sizedKeys lists the objects of an avatar or a stored object from its key
+// and the comma separated sizes of its variants.
+func sizedKeys(key string, sizes string) []string {
+	keys := []string{key}
+	for _, size := range strings.Split(sizes, ",") {
+		if n, err := strconv.Atoi(size); err == nil {
+			keys = append(keys, models.VariantKey(key, n))
+		}
+	}
+	return keys
+}
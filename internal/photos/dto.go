@@ -1,5 +1,97 @@
 package photos
 
+import "time"
+
 type UploadAvatarResponse struct {
-	URL string `json:"url"`
+	URL           string            `json:"url"`
+	Variants      map[string]string `json:"variants"`
+	BlurHash      string            `json:"blurhash"`
+	DominantColor string            `json:"dominant_color"`
+}
+
+type AvatarHistoryResponse struct {
+	ID            uint              `json:"id"`
+	URL           string            `json:"url"`
+	Variants      map[string]string `json:"variants"`
+	BlurHash      string            `json:"blurhash"`
+	DominantColor string            `json:"dominant_color"`
+	ReplacedAt    time.Time         `json:"replaced_at"`
+}
+
+type MediaResponse struct {
+	ID          uint                    `json:"id"`
+	URL         string                  `json:"url"`
+	ContentType string                  `json:"content_type"`
+	Size        int64                   `json:"size"`
+	Width       int                     `json:"width"`
+	Height      int                     `json:"height"`
+	Variants    []*MediaVariantResponse `json:"variants"`
+
+	BlurHash      string `json:"blurhash"`
+	DominantColor string `json:"dominant_color"`
+}
+
+type MediaVariantResponse struct {
+	Size   int    `json:"size"`
+	URL    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+type CreateUploadInput struct {
+	Purpose     string `json:"purpose" validate:"required,oneof=avatar post"`
+	ContentType string `json:"content_type" validate:"required"`
+	Size        int64  `json:"size" validate:"required,min=1"`
+}
+
+// UploadSlotResponse tells the client where to upload the file: a multipart
+// POST to URL with Fields as form values and the file last, in "file".
+type UploadSlotResponse struct {
+	ID        uint              `json:"id"`
+	Method    string            `json:"method"`
+	URL       string            `json:"url"`
+	Fields    map[string]string `json:"fields"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+// ConfirmUploadResponse holds the avatar or the media created from the
+// upload, depending on its purpose.
+type ConfirmUploadResponse struct {
+	Purpose string                `json:"purpose"`
+	Avatar  *UploadAvatarResponse `json:"avatar,omitempty"`
+	Media   *MediaResponse        `json:"media,omitempty"`
+}
+
+// GCReport describes a garbage collection run. Released lists the shared
+// objects without references past the grace period, and Orphans the keys of
+// the objects no row refers to that are past it; they are only deleted when
+// the run is not a dry run.
+type GCReport struct {
+	DryRun     bool     `json:"dry_run"`
+	Released   []string `json:"released"`
+	Purged     int      `json:"purged"`
+	Scanned    int      `json:"scanned"`
+	Referenced int      `json:"referenced"`
+	Recent     int      `json:"recent"`
+	Orphans    []string `json:"orphans"`
+	Deleted    int      `json:"deleted"`
+	FreedBytes int64    `json:"freed_bytes"`
+}
+
+// UsageResponse describes the storage taken by the media of a user. Pending
+// is reserved by direct uploads not confirmed yet. Quota and Remaining are
+// null when the role of the user has no limit.
+type UsageResponse struct {
+	MediaCount int64  `json:"media_count"`
+	Stored     int64  `json:"stored_bytes"`
+	Pending    int64  `json:"pending_bytes"`
+	Quota      *int64 `json:"quota_bytes"`
+	Remaining  *int64 `json:"remaining_bytes"`
+}
+
+// QuotaDetails are the details of errors.ErrQuotaExceeded.
+type QuotaDetails struct {
+	Quota     int64 `json:"quota_bytes"`
+	Used      int64 `json:"used_bytes"`
+	Requested int64 `json:"requested_bytes"`
 }
@@ -0,0 +1,150 @@
+package photos
+
+import (
+	"blog-api/internal/logger"
+	"blog-api/internal/models"
+	"blog-api/internal/storage"
+	"context"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// CollectGarbage deletes objects that no row refers to, left behind by failed
+// uploads, crashes between the storage and the database, or deleted users.
+// Objects younger than the grace period are spared, as uploads store their
+// objects before the rows referring to them are committed.
+//
+// Shared objects whose last reference was dropped before the grace period
+// and that PurgeObjects failed to remove are purged first, under the same
+// row lock.
+func (s *photoService) CollectGarbage(ctx context.Context, dryRun bool) (*GCReport, error) {
+	log := logger.FromCtx(ctx, s.logger).With(slog.Bool("dry_run", dryRun))
+
+	cutoff := time.Now().Add(-s.cfg.GCGracePeriod)
+	report := &GCReport{DryRun: dryRun, Orphans: []string{}, Released: []string{}}
+
+	var released []string
+	if err := s.db.WithContext(ctx).Model(&models.StoredObject{}).
+		Where("ref_count = 0 AND (released_at IS NULL OR released_at < ?)", cutoff).
+		Pluck("key", &released).Error; err != nil {
+		log.Error("failed to collect released objects", logger.Err(err))
+		return nil, err
+	}
+	for _, key := range released {
+		report.Released = append(report.Released, key)
+		if dryRun {
+			log.Info("released object", slog.String("key", key))
+			continue
+		}
+
+		purged, err := s.purgeObject(ctx, key)
+		if err != nil {
+			log.Error("failed to purge released object", slog.String("key", key), logger.Err(err))
+			continue
+		}
+		if purged {
+			report.Purged++
+		}
+	}
+
+	refs, err := s.referencedKeys(ctx)
+	if err != nil {
+		log.Error("failed to collect referenced keys", logger.Err(err))
+		return nil, err
+	}
+
+	var orphans []storage.ObjectInfo
+	err = s.store.List(ctx, "", func(obj storage.ObjectInfo) error {
+		report.Scanned++
+		switch _, ok := refs[obj.Key]; {
+		case ok:
+			report.Referenced++
+		case obj.LastModified.After(cutoff):
+			report.Recent++
+		default:
+			orphans = append(orphans, obj)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Error("failed to list objects", logger.Err(err))
+		return nil, err
+	}
+
+	// deleted only once listed, as not every backend lists consistently while
+	// objects are removed
+	for _, obj := range orphans {
+		report.Orphans = append(report.Orphans, obj.Key)
+		if dryRun {
+			log.Info("orphaned object",
+				slog.String("key", obj.Key),
+				slog.Int64("size", obj.Size),
+				slog.Time("last_modified", obj.LastModified),
+			)
+			continue
+		}
+
+		if err := s.store.Delete(ctx, obj.Key); err != nil {
+			log.Error("failed to delete orphaned object", slog.String("key", obj.Key), logger.Err(err))
+			continue
+		}
+		report.Deleted++
+		report.FreedBytes += obj.Size
+	}
+
+	log.Info("garbage collection finished",
+		slog.Int("released", len(report.Released)),
+		slog.Int("purged", report.Purged),
+		slog.Int("scanned", report.Scanned),
+		slog.Int("referenced", report.Referenced),
+		slog.Int("recent", report.Recent),
+		slog.Int("orphans", len(report.Orphans)),
+		slog.Int("deleted", report.Deleted),
+		slog.Int64("freed_bytes", report.FreedBytes),
+	)
+	return report, nil
+}
+
+// referencedKeys collects the key of every object a row refers to.
+func (s *photoService) referencedKeys(ctx context.Context) (map[string]struct{}, error) {
+	db := s.db.WithContext(ctx)
+	refs := make(map[string]struct{})
+
+	// avatars, their history and shared objects store variant sizes rather
+	// than keys
+	var current, replaced, shared []sizedRef
+	if err := db.Unscoped().Model(&models.User{}).
+		Select("avatar AS key, avatar_variants AS variants").
+		Where("avatar IS NOT NULL").
+		Scan(&current).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Model(&models.AvatarHistory{}).Select("key", "variants").Scan(&replaced).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Model(&models.StoredObject{}).Select("key", "variants").Where("ref_count > 0").Scan(&shared).Error; err != nil {
+		return nil, err
+	}
+	for _, a := range slices.Concat(current, replaced, shared) {
+		for _, key := range sizedKeys(a.Key, a.Variants) {
+			refs[key] = struct{}{}
+		}
+	}
+
+	for _, model := range []any{&models.Media{}, &models.MediaVariant{}, &models.Upload{}} {
+		var keys []string
+		if err := db.Model(model).Pluck("key", &keys).Error; err != nil {
+			return nil, err
+		}
+		for _, key := range keys {
+			refs[key] = struct{}{}
+		}
+	}
+	return refs, nil
+}
+
+type sizedRef struct {
+	Key      string
+	Variants string
+}
@@ -13,6 +13,13 @@ import (
 
 type IPhotoHandler interface {
 	UploadAvatar(ctx fiber.Ctx) error
+	DeleteAvatar(ctx fiber.Ctx) error
+	GetAvatarHistory(ctx fiber.Ctx) error
+	RevertAvatar(ctx fiber.Ctx) error
+	UploadPostMedia(ctx fiber.Ctx) error
+	CreateUpload(ctx fiber.Ctx) error
+	ConfirmUpload(ctx fiber.Ctx) error
+	GetUsage(ctx fiber.Ctx) error
 }
 
 type photoHandler struct {
@@ -47,3 +54,131 @@ func (h *photoHandler) UploadAvatar(ctx fiber.Ctx) error {
 
 	return ctx.JSON(response.NewResponse(res))
 }
+
+func (h *photoHandler) DeleteAvatar(ctx fiber.Ctx) error {
+	user := users.MustGetUser(ctx)
+	requestID := requestid.FromContext(ctx)
+
+	err := h.photoService.DeleteAvatar(
+		context.WithValue(ctx, logger.RequestIDKey, requestID),
+		user.UserID,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *photoHandler) GetAvatarHistory(ctx fiber.Ctx) error {
+	user := users.MustGetUser(ctx)
+	requestID := requestid.FromContext(ctx)
+
+	res, err := h.photoService.GetAvatarHistory(
+		context.WithValue(ctx, logger.RequestIDKey, requestID),
+		user.UserID,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(response.NewResponse(res))
+}
+
+func (h *photoHandler) RevertAvatar(ctx fiber.Ctx) error {
+	user := users.MustGetUser(ctx)
+	requestID := requestid.FromContext(ctx)
+	historyID := fiber.Params[uint](ctx, "id")
+
+	res, err := h.photoService.RevertAvatar(
+		context.WithValue(ctx, logger.RequestIDKey, requestID),
+		user.UserID,
+		historyID,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(response.NewResponse(res))
+}
+
+func (h *photoHandler) UploadPostMedia(ctx fiber.Ctx) error {
+	user := users.MustGetUser(ctx)
+	requestID := requestid.FromContext(ctx)
+
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		return errors.ErrInvalidFile
+	}
+
+	res, err := h.photoService.UploadPostMedia(
+		context.WithValue(ctx, logger.RequestIDKey, requestID),
+		user.UserID,
+		file,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(response.NewResponse(res))
+}
+
+func (h *photoHandler) CreateUpload(ctx fiber.Ctx) error {
+	user := users.MustGetUser(ctx)
+	requestID := requestid.FromContext(ctx)
+
+	var input CreateUploadInput
+	if err := ctx.Bind().JSON(&input); err != nil {
+		return err
+	}
+
+	res, err := h.photoService.CreateUpload(
+		context.WithValue(ctx, logger.RequestIDKey, requestID),
+		user.UserID,
+		input,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(response.NewResponse(res))
+}
+
+func (h *photoHandler) ConfirmUpload(ctx fiber.Ctx) error {
+	user := users.MustGetUser(ctx)
+	requestID := requestid.FromContext(ctx)
+	uploadID := fiber.Params[uint](ctx, "id")
+
+	res, err := h.photoService.ConfirmUpload(
+		context.WithValue(ctx, logger.RequestIDKey, requestID),
+		user.UserID,
+		uploadID,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(response.NewResponse(res))
+}
+
+func (h *photoHandler) GetUsage(ctx fiber.Ctx) error {
+	user := users.MustGetUser(ctx)
+	requestID := requestid.FromContext(ctx)
+
+	res, err := h.photoService.GetUsage(
+		context.WithValue(ctx, logger.RequestIDKey, requestID),
+		user.UserID,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(response.NewResponse(res))
+}
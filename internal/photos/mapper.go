@@ -0,0 +1,49 @@
+package photos
+
+import (
+	"blog-api/internal/models"
+	"blog-api/internal/users"
+)
+
+func MapMediaToResponse(media models.Media, url func(key string) string) *MediaResponse {
+	return &MediaResponse{
+		ID:          media.ID,
+		URL:         url(media.Key),
+		ContentType: media.ContentType,
+		Size:        media.Size,
+		Width:       media.Width,
+		Height:      media.Height,
+		Variants:    MapVariantsToResponse(media.Variants, url),
+
+		BlurHash:      media.BlurHash,
+		DominantColor: media.DominantColor,
+	}
+}
+
+func MapVariantsToResponse(variants []models.MediaVariant, url func(key string) string) []*MediaVariantResponse {
+	output := make([]*MediaVariantResponse, len(variants))
+	for i, v := range variants {
+		output[i] = &MediaVariantResponse{
+			Size:   v.Size,
+			URL:    url(v.Key),
+			Width:  v.Width,
+			Height: v.Height,
+		}
+	}
+	return output
+}
+
+func MapAvatarHistoryToResponse(history []models.AvatarHistory, url func(key string) string) []*AvatarHistoryResponse {
+	output := make([]*AvatarHistoryResponse, len(history))
+	for i, h := range history {
+		output[i] = &AvatarHistoryResponse{
+			ID:            h.ID,
+			URL:           url(h.Key),
+			Variants:      users.MapAvatarVariants(url, h.Key, h.Variants),
+			BlurHash:      h.BlurHash,
+			DominantColor: h.DominantColor,
+			ReplacedAt:    h.CreatedAt,
+		}
+	}
+	return output
+}
@@ -0,0 +1,136 @@
+package photos
+
+import (
+	"blog-api/internal/database"
+	"blog-api/internal/logger"
+	"blog-api/internal/models"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	goerrors "errors"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Post media is content-addressed: identical images share one object, whose
+// references are counted in models.StoredObject. The counts change in the
+// transactions adding and removing media rows, and an object is only removed
+// from the storage by PurgeObjects once nothing refers to it any more.
+
+// contentKey is the object key of processed image data.
+func contentKey(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "media/" + hex.EncodeToString(sum[:])
+}
+
+// acquireObject adds a reference to the object under key, creating its row
+// if needed, and returns the new reference count. The row stays locked until
+// the transaction ends. A count of 1 means the object may not be stored yet.
+func acquireObject(tx *gorm.DB, key string) (int, error) {
+	obj := models.StoredObject{Key: key, RefCount: 1}
+	err := tx.Clauses(
+		clause.OnConflict{
+			Columns:   []clause.Column{{Name: "key"}},
+			DoUpdates: clause.Assignments(map[string]any{"ref_count": gorm.Expr("stored_objects.ref_count + 1")}),
+		},
+		clause.Returning{Columns: []clause.Column{{Name: "ref_count"}}},
+	).Create(&obj).Error
+	return obj.RefCount, err
+}
+
+func setObjectVariants(tx *gorm.DB, key string, variants []models.MediaVariant) error {
+	sizes := make([]string, len(variants))
+	for i, v := range variants {
+		sizes[i] = strconv.Itoa(v.Size)
+	}
+	return tx.Model(&models.StoredObject{}).Where("key = ?", key).Update("variants", strings.Join(sizes, ",")).Error
+}
+
+// sharedVariants copies the variant rows of another media stored under key.
+// It returns nil if there is none.
+func sharedVariants(tx *gorm.DB, key string) ([]models.MediaVariant, error) {
+	var media models.Media
+	err := tx.Preload("Variants").Where("key = ?", key).First(&media).Error
+	if goerrors.Is(err, database.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	variants := make([]models.MediaVariant, len(media.Variants))
+	for i, v := range media.Variants {
+		v.ID, v.MediaID = 0, 0
+		variants[i] = v
+	}
+	return variants, nil
+}
+
+// ReleaseObjects drops a reference to the object under each key, one per
+// occurrence. It returns the keys nothing refers to any more, to be passed
+// to PurgeObjects once the transaction commits.
+func ReleaseObjects(tx *gorm.DB, keys []string) ([]string, error) {
+	var unused []string
+	for _, key := range keys {
+		var refs []int
+		if err := tx.Raw(
+			"UPDATE stored_objects SET ref_count = ref_count - 1, released_at = NOW() WHERE key = ? AND ref_count > 0 RETURNING ref_count",
+			key,
+		).Scan(&refs).Error; err != nil {
+			return nil, err
+		}
+		if len(refs) == 1 && refs[0] == 0 {
+			unused = append(unused, key)
+		}
+	}
+	return unused, nil
+}
+
+// PurgeObjects removes objects released by ReleaseObjects from the storage,
+// unless they were referenced again in the meantime. Failures are only
+// logged, the garbage collector picks those objects up later.
+func (s *photoService) PurgeObjects(ctx context.Context, keys []string) {
+	log := logger.FromCtx(ctx, s.logger)
+
+	for _, key := range keys {
+		if _, err := s.purgeObject(ctx, key); err != nil {
+			log.Error("failed to purge object", slog.String("key", key), logger.Err(err))
+		}
+	}
+}
+
+// purgeObject removes an object nothing refers to, with its variants and its
+// row. It reports false if the object is referenced or already gone.
+func (s *photoService) purgeObject(ctx context.Context, key string) (bool, error) {
+	purged := false
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var obj models.StoredObject
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("key = ? AND ref_count = 0", key).
+			First(&obj).Error
+		if goerrors.Is(err, database.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		// deleted under the row lock, so that nobody stores the same
+		// content again until the row is gone
+		for _, k := range sizedKeys(obj.Key, obj.Variants) {
+			if err := s.store.Delete(ctx, k); err != nil {
+				return err
+			}
+		}
+		if err := tx.Delete(&obj).Error; err != nil {
+			return err
+		}
+		purged = true
+		return nil
+	})
+	return purged, err
+}
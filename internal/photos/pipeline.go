@@ -0,0 +1,196 @@
+package photos
+
+import (
+	"blog-api/config"
+	"bytes"
+	goerrors "errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"slices"
+
+	xdraw "golang.org/x/image/draw"
+)
+
+// VariantContentType is the format of every generated variant. WebP would be
+// smaller, but golang.org/x/image only ships a WebP decoder.
+const VariantContentType = "image/jpeg"
+
+var ErrTooManyPixels = goerrors.New("image has too many pixels")
+
+type Variant struct {
+	Size   int
+	Width  int
+	Height int
+	Data   []byte
+}
+
+// Processed is an upload after the pipeline: re-encoded upright without any
+// metadata, plus its resized variants.
+type Processed struct {
+	Data        []byte
+	ContentType string
+	Width       int
+	Height      int
+	Variants    []Variant
+
+	BlurHash      string
+	DominantColor string
+}
+
+// Bytes is the storage taken by the image and its variants.
+func (p *Processed) Bytes() int64 {
+	n := int64(len(p.Data))
+	for _, v := range p.Variants {
+		n += int64(len(v.Data))
+	}
+	return n
+}
+
+// Pipeline turns an uploaded image into what gets stored.
+type Pipeline struct {
+	sizes     []int
+	quality   int
+	maxPixels int
+}
+
+func NewPipeline(cfg config.MediaConfig) *Pipeline {
+	sizes := slices.Clone(cfg.VariantSizes)
+	slices.Sort(sizes)
+
+	return &Pipeline{
+		sizes:     slices.Compact(sizes),
+		quality:   cfg.JPEGQuality,
+		maxPixels: cfg.MaxPixels,
+	}
+}
+
+// Process decodes the image, applies its EXIF orientation and re-encodes it,
+// which drops EXIF (GPS position, camera serial numbers) and any other
+// metadata. JPEG stays JPEG, PNG stays PNG and WebP, which cannot be
+// encoded, becomes PNG to keep transparency. Variants and placeholders are
+// rendered from the oriented image.
+//
+// The pixel count is checked against the header before decoding and against
+// the decoded image after, so a file lying about its size cannot make the
+// decoder allocate unbounded memory.
+func (p *Pipeline) Process(data []byte, contentType string) (*Processed, error) {
+	cfg, err := DecodeImageConfig(bytes.NewReader(data), contentType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	if cfg.Width*cfg.Height > p.maxPixels {
+		return nil, ErrTooManyPixels
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	if src.Bounds().Dx()*src.Bounds().Dy() > p.maxPixels {
+		return nil, ErrTooManyPixels
+	}
+
+	img := orient(src, exifOrientation(data, contentType))
+
+	processed := &Processed{
+		Width:  img.Bounds().Dx(),
+		Height: img.Bounds().Dy(),
+	}
+
+	if contentType == "image/jpeg" {
+		processed.ContentType = "image/jpeg"
+		processed.Data, err = p.encodeJPEG(img)
+	} else {
+		processed.ContentType = "image/png"
+		processed.Data, err = encodePNG(img)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	processed.Variants, err = p.variants(img)
+	if err != nil {
+		return nil, err
+	}
+
+	processed.BlurHash, processed.DominantColor, err = placeholder(img)
+	if err != nil {
+		return nil, err
+	}
+	return processed, nil
+}
+
+// variants renders one variant per configured size that is smaller than the
+// image. A variant keeps the aspect ratio and fits in a size x size box;
+// images are never upscaled.
+func (p *Pipeline) variants(src image.Image) ([]Variant, error) {
+	bounds := src.Bounds()
+	longest := max(bounds.Dx(), bounds.Dy())
+
+	var variants []Variant
+	for _, size := range p.sizes {
+		if size >= longest {
+			break
+		}
+
+		width, height := fit(bounds.Dx(), bounds.Dy(), size)
+		data, err := p.encodeJPEG(resize(src, width, height))
+		if err != nil {
+			return nil, err
+		}
+
+		variants = append(variants, Variant{
+			Size:   size,
+			Width:  width,
+			Height: height,
+			Data:   data,
+		})
+	}
+	return variants, nil
+}
+
+func (p *Pipeline) encodeJPEG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, flatten(img), &jpeg.Options{Quality: p.quality}); err != nil {
+		return nil, fmt.Errorf("failed to encode image: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func encodePNG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode image: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func fit(width, height, size int) (int, int) {
+	if width >= height {
+		return size, max(1, height*size/width)
+	}
+	return max(1, width*size/height), size
+}
+
+func resize(src image.Image, width, height int) image.Image {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), src, src.Bounds(), xdraw.Src, nil)
+	return dst
+}
+
+// flatten puts images that may be transparent over a white background, since
+// JPEG has no transparency.
+func flatten(img image.Image) image.Image {
+	switch img.(type) {
+	case *image.YCbCr, *image.Gray, *image.CMYK:
+		return img
+	}
+
+	dst := image.NewRGBA(img.Bounds())
+	xdraw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, xdraw.Src)
+	xdraw.Draw(dst, dst.Bounds(), img, img.Bounds().Min, xdraw.Over)
+	return dst
+}
@@ -0,0 +1,72 @@
+package photos
+
+import (
+	"blog-api/pkg/blurhash"
+	"fmt"
+	"image"
+)
+
+// placeholderSize is the bounding box of the thumbnail placeholders are
+// computed from; both only describe the rough picture.
+const placeholderSize = 32
+
+// placeholder computes what clients show while the image loads: a BlurHash
+// and the dominant color as #rrggbb. Transparent areas count as white, like
+// in the JPEG variants.
+func placeholder(img image.Image) (string, string, error) {
+	bounds := img.Bounds()
+	width, height := fit(bounds.Dx(), bounds.Dy(), placeholderSize)
+	thumb := flatten(resize(img, width, height))
+
+	// more components along the longer side
+	x, y := 4, 3
+	if height > width {
+		x, y = 3, 4
+	}
+	hash, err := blurhash.Encode(x, y, thumb)
+	if err != nil {
+		return "", "", err
+	}
+	return hash, dominantColor(thumb), nil
+}
+
+// dominantColor buckets pixels by their top 4 bits per channel and returns
+// the average color of the most populated bucket.
+func dominantColor(img image.Image) string {
+	type bucket struct {
+		count   int
+		r, g, b int
+	}
+	var (
+		buckets = make(map[uint32]*bucket)
+		best    *bucket
+	)
+
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, _ := img.At(x, y).RGBA()
+			r, g, b = r>>8, g>>8, b>>8
+
+			id := r>>4<<8 | g>>4<<4 | b>>4
+			bk, ok := buckets[id]
+			if !ok {
+				bk = &bucket{}
+				buckets[id] = bk
+			}
+			bk.count++
+			bk.r += int(r)
+			bk.g += int(g)
+			bk.b += int(b)
+
+			if best == nil || bk.count > best.count {
+				best = bk
+			}
+		}
+	}
+
+	if best == nil {
+		return "#ffffff"
+	}
+	return fmt.Sprintf("#%02x%02x%02x", best.r/best.count, best.g/best.count, best.b/best.count)
+}
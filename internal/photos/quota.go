@@ -0,0 +1,110 @@
+package photos
+
+import (
+	"blog-api/internal/errors"
+	"blog-api/internal/logger"
+	"blog-api/internal/models"
+	"context"
+	"log/slog"
+	"time"
+)
+
+func (s *photoService) GetUsage(ctx context.Context, userID uint) (*UsageResponse, error) {
+	log := logger.WithUserID(logger.FromCtx(ctx, s.logger), userID)
+
+	res := &UsageResponse{}
+	if err := s.db.WithContext(ctx).Model(&models.Media{}).
+		Where("user_id = ?", userID).
+		Count(&res.MediaCount).Error; err != nil {
+		log.Error("failed to count media", logger.Err(err))
+		return nil, err
+	}
+
+	var err error
+	if res.Stored, res.Pending, err = s.usage(ctx, userID, 0); err != nil {
+		log.Error("failed to compute storage usage", logger.Err(err))
+		return nil, err
+	}
+
+	quota, err := s.quota(ctx, userID)
+	if err != nil {
+		log.Error("failed to look up quota", logger.Err(err))
+		return nil, err
+	}
+	if quota > 0 {
+		remaining := max(quota-res.Stored-res.Pending, 0)
+		res.Quota, res.Remaining = &quota, &remaining
+	}
+	return res, nil
+}
+
+// checkQuota fails with errors.ErrQuotaExceeded when size more bytes would
+// take the user over the quota of their role, which covers post media with
+// their variants and pending direct uploads. The check is not atomic with
+// the upload, so concurrent uploads may overshoot the quota by a few files.
+func (s *photoService) checkQuota(ctx context.Context, userID uint, size int64, excludeUpload uint) error {
+	log := logger.WithUserID(logger.FromCtx(ctx, s.logger), userID)
+
+	quota, err := s.quota(ctx, userID)
+	if err != nil || quota == 0 {
+		return err
+	}
+
+	stored, pending, err := s.usage(ctx, userID, excludeUpload)
+	if err != nil {
+		return err
+	}
+
+	if used := stored + pending; used+size > quota {
+		log.Warn("storage quota exceeded",
+			slog.Int64("quota", quota),
+			slog.Int64("used", used),
+			slog.Int64("requested", size),
+		)
+		return errors.ErrQuotaExceeded.WithDetails(QuotaDetails{
+			Quota:     quota,
+			Used:      used,
+			Requested: size,
+		})
+	}
+	return nil
+}
+
+// quota returns the quota of the role of the user, 0 meaning no limit.
+func (s *photoService) quota(ctx context.Context, userID uint) (int64, error) {
+	var user models.User
+	if err := s.db.WithContext(ctx).Select("id", "role").First(&user, userID).Error; err != nil {
+		return 0, err
+	}
+	return s.cfg.Quotas[user.Role], nil
+}
+
+// usage returns the bytes stored by the media of the user and reserved by
+// their pending uploads, leaving excludeUpload out.
+func (s *photoService) usage(ctx context.Context, userID, excludeUpload uint) (stored, pending int64, err error) {
+	db := s.db.WithContext(ctx)
+
+	var originals, variants int64
+	if err = db.Model(&models.Media{}).
+		Select("COALESCE(SUM(size), 0)").
+		Where("user_id = ?", userID).
+		Scan(&originals).Error; err != nil {
+		return 0, 0, err
+	}
+	if err = db.Model(&models.MediaVariant{}).
+		Select("COALESCE(SUM(media_variants.bytes), 0)").
+		Joins("JOIN media ON media.id = media_variants.media_id").
+		Where("media.user_id = ?", userID).
+		Scan(&variants).Error; err != nil {
+		return 0, 0, err
+	}
+
+	if err = db.Model(&models.Upload{}).
+		Select("COALESCE(SUM(max_size), 0)").
+		Where("user_id = ? AND purpose = ? AND expires_at > ? AND id <> ?",
+			userID, models.UploadPurposePost, time.Now().UTC(), excludeUpload).
+		Scan(&pending).Error; err != nil {
+		return 0, 0, err
+	}
+	return originals + variants, pending, nil
+}
@@ -0,0 +1,47 @@
+package photos
+
+import (
+	"blog-api/internal/errors"
+	"blog-api/internal/logger"
+	"blog-api/internal/models"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+)
+
+// scan checks an upload for malware before anything is stored. Infected
+// files are rejected and recorded as a security event. When the scanner
+// cannot reach a verdict the upload fails too, rather than going through
+// unchecked.
+func (s *photoService) scan(ctx context.Context, userID uint, data []byte) error {
+	log := logger.WithUserID(logger.FromCtx(ctx, s.logger), userID)
+
+	result, err := s.scanner.Scan(ctx, bytes.NewReader(data))
+	if err != nil {
+		log.Error("malware scan failed", logger.Err(err))
+		return errors.ErrScanUnavailable
+	}
+	if !result.Infected {
+		return nil
+	}
+
+	sum := sha256.Sum256(data)
+	event := models.SecurityEvent{
+		UserID:  userID,
+		Type:    models.SecurityEventMalwareDetected,
+		Subject: hex.EncodeToString(sum[:]),
+		Detail:  result.Signature,
+	}
+
+	log.Warn("infected upload rejected",
+		slog.String("signature", event.Detail),
+		slog.String("sha256", event.Subject),
+	)
+
+	if err := s.db.WithContext(ctx).Create(&event).Error; err != nil {
+		log.Error("failed to record security event", logger.Err(err))
+	}
+	return errors.ErrMalwareDetected
+}
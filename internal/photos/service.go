@@ -1,34 +1,64 @@
 package photos
 
 import (
+	"blog-api/config"
 	"blog-api/internal/database"
 	"blog-api/internal/errors"
 	"blog-api/internal/logger"
 	"blog-api/internal/models"
+	"blog-api/internal/scanner"
 	"blog-api/internal/storage"
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"log/slog"
 	"mime/multipart"
-	"time"
+	"strconv"
+	"strings"
 
-	"github.com/minio/minio-go/v7"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type IPhotoService interface {
 	UploadAvatar(ctx context.Context, userID uint, file *multipart.FileHeader) (*UploadAvatarResponse, error)
+	UploadPostMedia(ctx context.Context, userID uint, file *multipart.FileHeader) (*MediaResponse, error)
+	MediaURL(key string) string
+	PurgeObjects(ctx context.Context, keys []string)
+
+	DeleteAvatar(ctx context.Context, userID uint) error
+	GetAvatarHistory(ctx context.Context, userID uint) ([]*AvatarHistoryResponse, error)
+	RevertAvatar(ctx context.Context, userID, historyID uint) (*UploadAvatarResponse, error)
+
+	CreateUpload(ctx context.Context, userID uint, input CreateUploadInput) (*UploadSlotResponse, error)
+	ConfirmUpload(ctx context.Context, userID, uploadID uint) (*ConfirmUploadResponse, error)
+	CleanupExpiredUploads(ctx context.Context) (int, error)
+	CleanupUnattachedMedia(ctx context.Context) (int, error)
+	CollectGarbage(ctx context.Context, dryRun bool) (*GCReport, error)
+
+	GetUsage(ctx context.Context, userID uint) (*UsageResponse, error)
 }
+
 type photoService struct {
-	db     *database.DB
-	minio  *storage.MinioClient
-	logger *slog.Logger
+	db       *database.DB
+	store    storage.ObjectStore
+	urls     storage.URLResolver
+	scanner  scanner.Scanner
+	pipeline *Pipeline
+	cfg      config.MediaConfig
+	logger   *slog.Logger
 }
 
-func NewPhotoService(db *database.DB, minio *storage.MinioClient, logger *slog.Logger) IPhotoService {
+func NewPhotoService(db *database.DB, store storage.ObjectStore, urls storage.URLResolver, scanner scanner.Scanner, cfg config.MediaConfig, logger *slog.Logger) IPhotoService {
 	return &photoService{
-		db:     db,
-		minio:  minio,
-		logger: logger,
+		db:       db,
+		store:    store,
+		urls:     urls,
+		scanner:  scanner,
+		pipeline: NewPipeline(cfg),
+		cfg:      cfg,
+		logger:   logger,
 	}
 }
 
@@ -37,51 +67,252 @@ func (s *photoService) UploadAvatar(ctx context.Context, userID uint, file *mult
 
 	log.Info("starting avatar upload")
 
-	if err := ValidateAvatar(file); err != nil {
+	info, err := ValidateAvatar(file)
+	if err != nil {
 		log.Warn("avatar validation failed", logger.Err(err))
 		return nil, errors.BadRequest(err.Error())
 	}
 
-	src, err := file.Open()
+	data, err := readFile(file)
 	if err != nil {
-		log.Error("failed to open uploaded file", logger.Err(err))
+		log.Error("failed to read uploaded file", logger.Err(err))
+		return nil, err
+	}
+
+	return s.saveAvatar(ctx, userID, data, info)
+}
+
+// saveAvatar scans and processes a validated image and makes it the user's
+// avatar.
+func (s *photoService) saveAvatar(ctx context.Context, userID uint, data []byte, info *PhotoInfo) (*UploadAvatarResponse, error) {
+	log := logger.WithUserID(logger.FromCtx(ctx, s.logger), userID)
+
+	if err := s.scan(ctx, userID, data); err != nil {
 		return nil, err
 	}
-	defer func() {
-		if err := src.Close(); err != nil {
-			log.Warn("failed to close file source", logger.Err(err))
+
+	processed, err := s.process(data, info.ContentType)
+	if err != nil {
+		log.Warn("avatar processing failed", logger.Err(err))
+		return nil, err
+	}
+
+	// every upload gets a new key, so cached copies of the previous avatar
+	// are never served in its place
+	key := fmt.Sprintf("avatars/%d/%s", userID, uuid.NewString())
+
+	log.Info("uploading file to storage")
+	variants, err := s.storeImage(ctx, key, processed)
+	if err != nil {
+		log.Error("failed to store avatar", logger.Err(err))
+		return nil, err
+	}
+
+	sizes := make([]string, len(variants))
+	for i, v := range variants {
+		sizes[i] = strconv.Itoa(v.Size)
+	}
+	avatar := models.AvatarHistory{
+		Key:           key,
+		Variants:      strings.Join(sizes, ","),
+		BlurHash:      processed.BlurHash,
+		DominantColor: processed.DominantColor,
+	}
+
+	var stale []string
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		user, err := lockAvatar(tx, userID)
+		if err != nil {
+			return err
+		}
+
+		if stale, err = s.retireAvatar(tx, user); err != nil {
+			return err
 		}
-	}()
 
-	filename := fmt.Sprintf("avatars/%d", userID)
-	url := fmt.Sprintf("http://%s/%s/%s?ts=%d", s.minio.Client.EndpointURL().Host, s.minio.Bucket, filename, time.Now().UTC().UnixNano())
+		return setAvatar(tx, userID, avatar)
+	})
+	if err != nil {
+		log.Error("failed to update user avatar in database", logger.Err(err))
+
+		log.Warn("attempting to rollback: delete uploaded file from storage")
+		s.DeleteObjects(ctx, objectKeys(key, variants))
 
-	db := s.db.WithContext(ctx)
+		return nil, err
+	}
+
+	s.DeleteObjects(ctx, stale)
+
+	log.Info("avatar uploaded successfully", slog.String("key", key), slog.Int("variants", len(variants)))
+
+	return s.avatarResponse(avatar), nil
+}
 
-	log.Info("uploading file to minio storage")
-	_, err = s.minio.Client.PutObject(ctx, s.minio.Bucket, filename, src, file.Size, minio.PutObjectOptions{
-		ContentType: file.Header.Get("Content-Type"),
+func (s *photoService) UploadPostMedia(ctx context.Context, userID uint, file *multipart.FileHeader) (*MediaResponse, error) {
+	log := logger.WithUserID(logger.FromCtx(ctx, s.logger), userID)
+
+	log.Info("starting post media upload")
+
+	info, err := ValidatePostMedia(file)
+	if err != nil {
+		log.Warn("post media validation failed", logger.Err(err))
+		return nil, errors.BadRequest(err.Error())
+	}
+
+	data, err := readFile(file)
+	if err != nil {
+		log.Error("failed to read uploaded file", logger.Err(err))
+		return nil, err
+	}
+
+	return s.saveMedia(ctx, userID, data, info, 0)
+}
+
+// saveMedia scans and processes a validated image and stores it as media of
+// the user, ready to be attached to a post. uploadID is the direct upload the
+// image comes from, if any, so that its reservation is not counted twice.
+func (s *photoService) saveMedia(ctx context.Context, userID uint, data []byte, info *PhotoInfo, uploadID uint) (*MediaResponse, error) {
+	log := logger.WithUserID(logger.FromCtx(ctx, s.logger), userID)
+
+	if err := s.scan(ctx, userID, data); err != nil {
+		return nil, err
+	}
+
+	processed, err := s.process(data, info.ContentType)
+	if err != nil {
+		log.Warn("post media processing failed", logger.Err(err))
+		return nil, err
+	}
+
+	if err := s.checkQuota(ctx, userID, processed.Bytes(), uploadID); err != nil {
+		return nil, err
+	}
+
+	key := contentKey(processed.Data)
+	media := models.Media{
+		UserID:      userID,
+		Key:         key,
+		ContentType: processed.ContentType,
+		Size:        int64(len(processed.Data)),
+		Width:       processed.Width,
+		Height:      processed.Height,
+
+		BlurHash:      processed.BlurHash,
+		DominantColor: processed.DominantColor,
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		refs, err := acquireObject(tx, key)
+		if err != nil {
+			return err
+		}
+
+		if refs > 1 {
+			if media.Variants, err = sharedVariants(tx, key); err != nil {
+				return err
+			}
+		}
+
+		// the first reference stores the object; the row stays locked
+		// meanwhile, so identical uploads wait for it instead of racing
+		if refs == 1 || media.Variants == nil {
+			if media.Variants, err = s.storeImage(ctx, key, processed); err != nil {
+				return err
+			}
+			if err := setObjectVariants(tx, key, media.Variants); err != nil {
+				return err
+			}
+		}
+
+		return tx.Create(&media).Error
 	})
+	if err != nil {
+		// objects stored before the failure are left to the garbage
+		// collector: an identical upload may already be storing them again
+		log.Error("failed to save post media", logger.Err(err))
+		return nil, err
+	}
 
+	log.Info("post media uploaded successfully",
+		slog.Uint64("media_id", uint64(media.ID)),
+		slog.Int("variants", len(media.Variants)),
+	)
+
+	return MapMediaToResponse(media, s.MediaURL), nil
+}
+
+// MediaURL returns the URL clients download an object from.
+func (s *photoService) MediaURL(key string) string {
+	return s.urls.URL(key)
+}
+
+// DeleteObjects removes objects from the storage. Failures are only logged:
+// the rows referencing the objects are gone by the time it is called.
+func (s *photoService) DeleteObjects(ctx context.Context, keys []string) {
+	log := logger.FromCtx(ctx, s.logger)
+
+	for _, key := range keys {
+		if err := s.store.Delete(ctx, key); err != nil {
+			log.Error("failed to delete object from storage", slog.String("key", key), logger.Err(err))
+		}
+	}
+}
+
+// process runs an upload through the pipeline. Every failure is caused by the
+// file itself, so they are all reported as bad requests.
+func (s *photoService) process(data []byte, contentType string) (*Processed, error) {
+	processed, err := s.pipeline.Process(data, contentType)
 	if err != nil {
-		log.Error("failed to upload file to minio", logger.Err(err))
+		return nil, errors.BadRequest(err.Error())
+	}
+	return processed, nil
+}
+
+// storeImage uploads a processed image and its variants. If any step fails,
+// the objects stored so far are removed again.
+func (s *photoService) storeImage(ctx context.Context, key string, processed *Processed) ([]models.MediaVariant, error) {
+	if err := s.putObject(ctx, key, bytes.NewReader(processed.Data), int64(len(processed.Data)), processed.ContentType); err != nil {
 		return nil, err
 	}
 
-	if err = db.Model(&models.User{}).Where("id = ?", userID).Update("avatar", url).Error; err != nil {
-		log.Error("failed to update user avatar in database", logger.Err(err))
+	stored := make([]models.MediaVariant, 0, len(processed.Variants))
+	for _, v := range processed.Variants {
+		variant := models.MediaVariant{
+			Size:        v.Size,
+			Key:         models.VariantKey(key, v.Size),
+			ContentType: VariantContentType,
+			Bytes:       int64(len(v.Data)),
+			Width:       v.Width,
+			Height:      v.Height,
+		}
 
-		log.Warn("attempting to rollback: delete uploaded file from minio")
-		if delErr := s.minio.Client.RemoveObject(ctx, s.minio.Bucket, filename, minio.RemoveObjectOptions{}); delErr != nil {
-			log.Error("failed to delete file during rollback", logger.Err(delErr))
+		if err := s.putObject(ctx, variant.Key, bytes.NewReader(v.Data), int64(len(v.Data)), VariantContentType); err != nil {
+			s.DeleteObjects(ctx, objectKeys(key, stored))
+			return nil, err
 		}
+		stored = append(stored, variant)
+	}
+	return stored, nil
+}
 
+func (s *photoService) putObject(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	return s.store.Put(ctx, key, r, size, contentType)
+}
+
+func readFile(file *multipart.FileHeader) ([]byte, error) {
+	src, err := file.Open()
+	if err != nil {
 		return nil, err
 	}
+	defer src.Close()
 
-	log.Info("avatar uploaded successfully", slog.String("url", url))
+	return io.ReadAll(src)
+}
 
-	return &UploadAvatarResponse{
-		URL: url,
-	}, nil
+func objectKeys(key string, variants []models.MediaVariant) []string {
+	keys := []string{key}
+	for _, v := range variants {
+		keys = append(keys, v.Key)
+	}
+	return keys
 }
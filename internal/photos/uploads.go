@@ -0,0 +1,280 @@
+package photos
+
+import (
+	"blog-api/internal/database"
+	"blog-api/internal/errors"
+	"blog-api/internal/logger"
+	"blog-api/internal/models"
+	"blog-api/internal/storage"
+	"context"
+	goerrors "errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreateUpload hands out a slot for uploading a file straight to the object
+// storage, bypassing the API body limit. The declared content type and size
+// are enforced by the storage; the file itself is validated on confirmation.
+func (s *photoService) CreateUpload(ctx context.Context, userID uint, input CreateUploadInput) (*UploadSlotResponse, error) {
+	log := logger.WithUserID(logger.FromCtx(ctx, s.logger), userID)
+
+	presigner, ok := s.store.(storage.Presigner)
+	if !ok {
+		return nil, errors.ErrDirectUploadsUnsupported
+	}
+
+	criteria, ok := Criteria(input.Purpose)
+	if !ok {
+		return nil, errors.BadRequest(fmt.Sprintf("invalid purpose: %s", input.Purpose))
+	}
+	if !slices.Contains(criteria.AllowedFormats, input.ContentType) {
+		return nil, errors.BadRequest(fmt.Sprintf("invalid content type: %s", input.ContentType))
+	}
+	if input.Size > criteria.MaxFileSize {
+		return nil, errors.BadRequest(fmt.Sprintf("file size exceeds the limit: %d", criteria.MaxFileSize))
+	}
+
+	if input.Purpose == models.UploadPurposePost {
+		if err := s.checkQuota(ctx, userID, input.Size, 0); err != nil {
+			return nil, err
+		}
+	}
+
+	upload := models.Upload{
+		UserID:      userID,
+		Purpose:     input.Purpose,
+		Key:         fmt.Sprintf("uploads/%d/%s", userID, uuid.NewString()),
+		ContentType: input.ContentType,
+		MaxSize:     input.Size,
+		ExpiresAt:   time.Now().UTC().Add(s.cfg.UploadTTL),
+	}
+
+	presigned, err := presigner.PresignUpload(ctx, upload.Key, upload.ContentType, upload.MaxSize, upload.ExpiresAt)
+	if err != nil {
+		log.Error("failed to presign upload", logger.Err(err))
+		return nil, err
+	}
+
+	if err := s.db.WithContext(ctx).Create(&upload).Error; err != nil {
+		log.Error("failed to save upload", logger.Err(err))
+		return nil, err
+	}
+
+	log.Info("upload slot created", slog.Uint64("upload_id", uint64(upload.ID)), slog.String("purpose", upload.Purpose))
+
+	return &UploadSlotResponse{
+		ID:        upload.ID,
+		Method:    "POST",
+		URL:       presigned.URL,
+		Fields:    presigned.Fields,
+		ExpiresAt: upload.ExpiresAt,
+	}, nil
+}
+
+// ConfirmUpload validates a file uploaded to a slot and turns it into an
+// avatar or media exactly like a regular upload. The slot is claimed first so
+// that concurrent confirmations cannot use it twice, and is removed with the
+// raw object once that succeeds.
+func (s *photoService) ConfirmUpload(ctx context.Context, userID, uploadID uint) (*ConfirmUploadResponse, error) {
+	log := logger.WithUserID(logger.FromCtx(ctx, s.logger), userID).
+		With(slog.Uint64("upload_id", uint64(uploadID)))
+
+	db := s.db.WithContext(ctx)
+
+	var upload models.Upload
+	if err := db.Where("id = ? AND user_id = ?", uploadID, userID).First(&upload).Error; err != nil {
+		if goerrors.Is(err, database.ErrRecordNotFound) {
+			return nil, errors.ErrNotFound
+		}
+		return nil, err
+	}
+
+	if time.Now().After(upload.ExpiresAt) {
+		return nil, errors.ErrUploadExpired
+	}
+
+	claim := db.Model(&upload).Where("confirmed_at IS NULL").Update("confirmed_at", time.Now().UTC())
+	if claim.Error != nil {
+		log.Error("failed to claim upload", logger.Err(claim.Error))
+		return nil, claim.Error
+	}
+	if claim.RowsAffected == 0 {
+		log.Warn("upload is already being confirmed")
+		return nil, errors.ErrUploadInProgress
+	}
+
+	consumed := false
+	defer func() {
+		if consumed {
+			return
+		}
+		// the slot can be confirmed again, e.g. once the file has arrived;
+		// if this fails it stays claimed until the cleanup removes it
+		if err := db.Model(&upload).Update("confirmed_at", nil).Error; err != nil {
+			log.Error("failed to release upload", logger.Err(err))
+		}
+	}()
+
+	criteria, ok := Criteria(upload.Purpose)
+	if !ok {
+		return nil, fmt.Errorf("unknown upload purpose: %s", upload.Purpose)
+	}
+
+	data, err := s.readObject(ctx, upload.Key, criteria.MaxFileSize)
+	if err != nil {
+		if goerrors.Is(err, storage.ErrObjectNotFound) {
+			return nil, errors.ErrUploadNotReceived
+		}
+		log.Error("failed to read uploaded object", logger.Err(err))
+		return nil, err
+	}
+
+	info, err := ValidatePhotoData(data, criteria)
+	if err != nil {
+		log.Warn("upload validation failed", logger.Err(err))
+		return nil, errors.BadRequest(err.Error())
+	}
+
+	res := &ConfirmUploadResponse{Purpose: upload.Purpose}
+	switch upload.Purpose {
+	case models.UploadPurposeAvatar:
+		res.Avatar, err = s.saveAvatar(ctx, userID, data, info)
+	case models.UploadPurposePost:
+		res.Media, err = s.saveMedia(ctx, userID, data, info, upload.ID)
+	}
+	if err != nil {
+		if goerrors.Is(err, errors.ErrMalwareDetected) {
+			// an infected file is not kept around until the slot expires
+			consumed = true
+			s.DeleteObjects(ctx, []string{upload.Key})
+			if err := db.Delete(&upload).Error; err != nil {
+				log.Error("failed to delete rejected upload", logger.Err(err))
+			}
+		}
+		return nil, err
+	}
+	consumed = true
+
+	if err := db.Delete(&upload).Error; err != nil {
+		// the slot stays claimed and the cleanup takes the object with it
+		log.Error("failed to delete confirmed upload", logger.Err(err))
+		return res, nil
+	}
+	s.DeleteObjects(ctx, []string{upload.Key})
+
+	log.Info("upload confirmed", slog.String("purpose", upload.Purpose))
+	return res, nil
+}
+
+// CleanupExpiredUploads removes slots that were not confirmed in time and
+// whatever was uploaded to them. It returns the number of slots removed.
+func (s *photoService) CleanupExpiredUploads(ctx context.Context) (int, error) {
+	log := logger.FromCtx(ctx, s.logger)
+
+	var uploads []models.Upload
+	if err := s.db.WithContext(ctx).
+		Where("expires_at < ?", time.Now().UTC()).
+		Limit(500).
+		Find(&uploads).Error; err != nil {
+		return 0, err
+	}
+	if len(uploads) == 0 {
+		return 0, nil
+	}
+
+	// a slot is only dropped once its object is gone, so that an object
+	// failing to delete is retried on the next run instead of leaking
+	ids := make([]uint, 0, len(uploads))
+	for _, u := range uploads {
+		if err := s.store.Delete(ctx, u.Key); err != nil {
+			log.Error("failed to delete expired upload object", slog.String("key", u.Key), logger.Err(err))
+			continue
+		}
+		ids = append(ids, u.ID)
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	if err := s.db.WithContext(ctx).Delete(&models.Upload{}, ids).Error; err != nil {
+		return 0, err
+	}
+
+	log.Info("expired uploads removed", slog.Int("count", len(ids)))
+	return len(ids), nil
+}
+
+// CleanupUnattachedMedia deletes post media that was not attached to any
+// post within UnattachedMediaTTL, releasing its objects and its quota.
+func (s *photoService) CleanupUnattachedMedia(ctx context.Context) (int, error) {
+	log := logger.FromCtx(ctx, s.logger)
+
+	cutoff := time.Now().UTC().Add(-s.cfg.UnattachedMediaTTL)
+	var unused []string
+	var count int
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// locking the rows makes a post attaching one of them meanwhile wait
+		// for the deletion and fail instead of referring to a deleted media
+		var media []models.Media
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("created_at < ?", cutoff).
+			Where("NOT EXISTS (SELECT 1 FROM post_attachments WHERE post_attachments.media_id = media.id)").
+			Limit(500).
+			Find(&media).Error; err != nil {
+			return err
+		}
+		if len(media) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, len(media))
+		keys := make([]string, len(media))
+		for i, m := range media {
+			ids[i], keys[i] = m.ID, m.Key
+		}
+		if err := tx.Delete(&models.Media{}, ids).Error; err != nil {
+			return err
+		}
+
+		var err error
+		unused, err = ReleaseObjects(tx, keys)
+		count = len(media)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	if count == 0 {
+		return 0, nil
+	}
+
+	s.PurgeObjects(ctx, unused)
+
+	log.Info("unattached media removed", slog.Int("count", count))
+	return count, nil
+}
+
+// readObject reads an object, failing if it is larger than maxSize.
+func (s *photoService) readObject(ctx context.Context, key string, maxSize int64) ([]byte, error) {
+	r, err := s.store.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	data, err := io.ReadAll(io.LimitReader(r, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxSize {
+		return nil, errors.BadRequest(fmt.Sprintf("file size exceeds the limit: %d", maxSize))
+	}
+	return data, nil
+}
@@ -1,6 +1,8 @@
 package photos
 
 import (
+	"blog-api/internal/models"
+	"bytes"
 	goerrors "errors"
 	"fmt"
 	"io"
@@ -30,53 +32,100 @@ var (
 		MinAspectRatio: 0.25,
 		MaxAspectRatio: 3.0,
 	}
-)
 
-func ValidatePhoto(fileHeader *multipart.FileHeader, criteria ValidationCriteria) error {
-	if fileHeader.Size > criteria.MaxFileSize {
-		return fmt.Errorf("file size exceeds the limit: %d", criteria.MaxFileSize)
+	PostMediaCriteria = ValidationCriteria{
+		MaxFileSize:    8 << 20, // 8MB
+		AllowedFormats: []string{"image/jpeg", "image/png", "image/webp"},
+		MinWidth:       32,
+		MinHeight:      32,
+		MaxWidth:       8192,
+		MaxHeight:      8192,
+		MinAspectRatio: 0.1,
+		MaxAspectRatio: 10.0,
 	}
+)
+
+// PhotoInfo describes an image that passed validation.
+type PhotoInfo struct {
+	ContentType string
+	Width       int
+	Height      int
+}
 
+func ValidatePhoto(fileHeader *multipart.FileHeader, criteria ValidationCriteria) (*PhotoInfo, error) {
 	file, err := fileHeader.Open()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer file.Close()
 
+	return validateImage(file, fileHeader.Size, criteria)
+}
+
+// ValidatePhotoData is ValidatePhoto for a file that is already in memory,
+// such as one read back from the object storage.
+func ValidatePhotoData(data []byte, criteria ValidationCriteria) (*PhotoInfo, error) {
+	return validateImage(bytes.NewReader(data), int64(len(data)), criteria)
+}
+
+func validateImage(file io.ReadSeeker, size int64, criteria ValidationCriteria) (*PhotoInfo, error) {
+	if size > criteria.MaxFileSize {
+		return nil, fmt.Errorf("file size exceeds the limit: %d", criteria.MaxFileSize)
+	}
+
 	contentType, err := DetectContentType(file)
 	if err != nil {
-		return goerrors.New("failed to detect content type")
+		return nil, goerrors.New("failed to detect content type")
 	}
 
 	if !slices.Contains(criteria.AllowedFormats, contentType) {
-		return fmt.Errorf("invalid content type: %s", contentType)
+		return nil, fmt.Errorf("invalid content type: %s", contentType)
 	}
 
 	if _, err := file.Seek(0, io.SeekStart); err != nil {
-		return err
+		return nil, err
 	}
 
 	img, err := DecodeImageConfig(file, contentType)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if img.Width < criteria.MinWidth || img.Height < criteria.MinHeight {
-		return fmt.Errorf("image dimensions too small: MinWidth: %d, MinHeight: %d", criteria.MinWidth, criteria.MinHeight)
+		return nil, fmt.Errorf("image dimensions too small: MinWidth: %d, MinHeight: %d", criteria.MinWidth, criteria.MinHeight)
 	}
 
 	if img.Width > criteria.MaxWidth || img.Height > criteria.MaxHeight {
-		return fmt.Errorf("image dimensions too large: MaxWidth: %d, MaxHeight: %d", criteria.MaxWidth, criteria.MaxHeight)
+		return nil, fmt.Errorf("image dimensions too large: MaxWidth: %d, MaxHeight: %d", criteria.MaxWidth, criteria.MaxHeight)
 	}
 
 	aspectRatio := float64(img.Width) / float64(img.Height)
 	if aspectRatio < criteria.MinAspectRatio || aspectRatio > criteria.MaxAspectRatio {
-		return fmt.Errorf("aspect ratio must be between %.2f and %.2f", criteria.MinAspectRatio, criteria.MaxAspectRatio)
+		return nil, fmt.Errorf("aspect ratio must be between %.2f and %.2f", criteria.MinAspectRatio, criteria.MaxAspectRatio)
 	}
 
-	return nil
+	return &PhotoInfo{
+		ContentType: contentType,
+		Width:       img.Width,
+		Height:      img.Height,
+	}, nil
 }
 
-func ValidateAvatar(fileHeader *multipart.FileHeader) error {
+func ValidateAvatar(fileHeader *multipart.FileHeader) (*PhotoInfo, error) {
 	return ValidatePhoto(fileHeader, AvatarCriteria)
 }
+
+func ValidatePostMedia(fileHeader *multipart.FileHeader) (*PhotoInfo, error) {
+	return ValidatePhoto(fileHeader, PostMediaCriteria)
+}
+
+// Criteria returns the validation criteria for an upload purpose.
+func Criteria(purpose string) (ValidationCriteria, bool) {
+	switch purpose {
+	case models.UploadPurposeAvatar:
+		return AvatarCriteria, true
+	case models.UploadPurposePost:
+		return PostMediaCriteria, true
+	}
+	return ValidationCriteria{}, false
+}
@@ -0,0 +1,64 @@
+package posts
+
+import (
+	"blog-api/internal/models"
+	"blog-api/pkg/cursor"
+	"time"
+)
+
+// Cursor identifies a position in a post listing ordered by (sort key, id).
+// It is handed to clients as an opaque string and carries the ordering it
+// was created with, so follow-up pages keep the same order.
+type Cursor struct {
+	OrderBy  string    `json:"o"`
+	Reaction string    `json:"r,omitempty"`
+	Time     time.Time `json:"t,omitzero"`
+	Count    int64     `json:"n,omitempty"`
+	ID       uint      `json:"id"`
+	Desc     bool      `json:"d,omitempty"`
+	Backward bool      `json:"b,omitempty"`
+}
+
+func NewCursor(post models.Post, order Order, backward bool) Cursor {
+	c := Cursor{
+		OrderBy:  order.By,
+		Reaction: order.Reaction,
+		ID:       post.ID,
+		Desc:     order.Desc,
+		Backward: backward,
+	}
+
+	switch order.By {
+	case OrderByUpdatedAt:
+		c.Time = post.UpdatedAt
+	case OrderByReactions, OrderByReaction:
+		c.Count = post.ReactionCount
+	default:
+		c.Time = post.CreatedAt
+	}
+	return c
+}
+
+func (c Cursor) Order() Order {
+	return Order{
+		By:       c.OrderBy,
+		Reaction: c.Reaction,
+		Desc:     c.Desc,
+	}
+}
+
+func (c Cursor) value() any {
+	switch c.OrderBy {
+	case OrderByReactions, OrderByReaction:
+		return c.Count
+	}
+	return c.Time
+}
+
+func EncodeCursor(c Cursor) (string, error) {
+	return cursor.Encode(c)
+}
+
+func DecodeCursor(s string) (Cursor, error) {
+	return cursor.Decode[Cursor](s)
+}
@@ -2,38 +2,81 @@ package posts
 
 import (
 	"blog-api/internal/models"
+	"blog-api/internal/photos"
 	"blog-api/internal/users"
 	"time"
 )
 
 type PostEntityInput struct {
-	Offset int     `json:"offset" validate:"min=0"`
-	Length int     `json:"length" validate:"min=1"`
-	Type   string  `json:"type" validate:"required,oneof=bold italic spoiler link underline"`
-	URL    *string `json:"url" validate:"omitempty,http_url,max=500"`
+	Offset   int     `json:"offset" validate:"min=0"`
+	Length   int     `json:"length" validate:"min=1"`
+	Type     string  `json:"type" validate:"required,oneof=bold italic underline strikethrough spoiler link code pre blockquote mention hashtag"`
+	URL      *string `json:"url" validate:"omitempty,http_url,max=500"`
+	Language *string `json:"language,omitempty" validate:"omitempty,max=30"`
+	UserID   *uint   `json:"user_id,omitempty" validate:"omitempty,min=1"`
+}
+
+type PostMediaInput struct {
+	MediaID uint   `json:"media_id" validate:"required"`
+	AltText string `json:"alt_text" validate:"max=1000"`
+	Caption string `json:"caption" validate:"max=2000"`
 }
 
 type CreatePostInput struct {
-	Title   string `json:"title" validate:"required,min=1,max=255"`
-	Content string `json:"content" validate:"required,min=1"`
+	Title       string `json:"title" validate:"required,min=1,max=255"`
+	Content     string `json:"content" validate:"required,min=1"`
+	InputFormat string `json:"input_format" validate:"omitempty,oneof=plain markdown"`
+	OffsetUnit  string `json:"offset_unit" validate:"omitempty,oneof=utf16 codepoint byte"`
 
 	Entities []PostEntityInput `json:"entities" validate:"omitempty,dive"`
+	Media    []PostMediaInput  `json:"media" validate:"omitempty,max=10,dive"`
+}
+
+// UpdatePostInput replaces the text of a post. Its attachments are kept when
+// Media is omitted; an empty list removes them all.
+type UpdatePostInput struct {
+	CreatePostInput
+	Media *[]PostMediaInput `json:"media" validate:"omitempty,max=10,dive"`
+}
+
+type PostMediaResponse struct {
+	ID          uint                           `json:"id"`
+	URL         string                         `json:"url"`
+	ContentType string                         `json:"content_type"`
+	Width       int                            `json:"width"`
+	Height      int                            `json:"height"`
+	AltText     string                         `json:"alt_text"`
+	Caption     string                         `json:"caption"`
+	Variants    []*photos.MediaVariantResponse `json:"variants"`
+
+	BlurHash      string `json:"blurhash"`
+	DominantColor string `json:"dominant_color"`
+
+	media models.Media
 }
 
 type PostResponse struct {
-	ID        uint      `json:"id"`
-	AuthorID  uint      `json:"author_id"`
-	Title     string    `json:"title"`
-	Content   string    `json:"content"`
-	CreatedAt time.Time `json:"created_at"`
-
-	Author       *users.UserResponse   `json:"author,omitempty"`
-	Entities     []*PostEntityInput    `json:"entities"`
-	UserReaction *models.UserReaction  `json:"user_reaction,omitempty"`
-	Reactions    []models.ReactionStat `json:"reactions"`
+	ID         uint      `json:"id"`
+	AuthorID   uint      `json:"author_id"`
+	Title      string    `json:"title"`
+	Content    string    `json:"content"`
+	OffsetUnit string    `json:"offset_unit"`
+	CreatedAt  time.Time `json:"created_at"`
+	Format     string    `json:"format,omitempty"`
+	Rendered   string    `json:"rendered,omitempty"`
+
+	Author        *users.UserResponse   `json:"author,omitempty"`
+	Entities      []*PostEntityInput    `json:"entities"`
+	Media         []*PostMediaResponse  `json:"media"`
+	UserReactions []models.UserReaction `json:"user_reactions,omitempty"`
+	Reactions     []models.ReactionStat `json:"reactions"`
+	CommentsCount int64                 `json:"comments_count"`
+	IsBookmarked  *bool                 `json:"is_bookmarked,omitempty"`
 }
 
 type ListResponse struct {
-	Total  int64           `json:"total"`
-	Result []*PostResponse `json:"result"`
+	Total      *int64          `json:"total,omitempty"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+	PrevCursor string          `json:"prev_cursor,omitempty"`
+	Result     []*PostResponse `json:"result"`
 }
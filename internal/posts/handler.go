@@ -65,10 +65,16 @@ func (h *postHandler) GetPost(ctx fiber.Ctx) error {
 		userID = &user.UserID
 	}
 
+	var params PostParams
+	if err := ctx.Bind().Query(&params); err != nil {
+		return errors.ErrInvalidQuery
+	}
+
 	post, err := h.postService.GetPost(
 		context.WithValue(ctx, logger.RequestIDKey, requestID),
 		postId,
 		userID,
+		params,
 	)
 
 	if err != nil {
@@ -105,7 +111,7 @@ func (h *postHandler) GetPosts(ctx fiber.Ctx) error {
 		return err
 	}
 
-	return ctx.JSON(response.NewPaginatedResponse(data.Total, data.Result))
+	return ctx.JSON(response.NewCursorPaginatedResponse(data.Total, data.NextCursor, data.PrevCursor, data.Result))
 }
 
 func (h *postHandler) UpdatePost(ctx fiber.Ctx) error {
@@ -114,7 +120,7 @@ func (h *postHandler) UpdatePost(ctx fiber.Ctx) error {
 
 	requestID := requestid.FromContext(ctx)
 
-	var input CreatePostInput
+	var input UpdatePostInput
 	if err := ctx.Bind().JSON(&input); err != nil {
 		return err
 	}
@@ -20,15 +20,19 @@ func MapPostToResponse(post models.Post) *PostResponse {
 
 	author := users.MapUserToResponse(post.Author)
 	return &PostResponse{
-		ID:           post.ID,
-		AuthorID:     post.AuthorID,
-		Title:        post.Title,
-		Content:      post.Content,
-		CreatedAt:    post.CreatedAt,
-		Author:       author,
-		Entities:     MapEntitiesToResponse(post.Entities),
-		UserReaction: post.UserReaction,
-		Reactions:    post.Reactions,
+		ID:            post.ID,
+		AuthorID:      post.AuthorID,
+		Title:         post.Title,
+		Content:       post.Content,
+		CreatedAt:     post.CreatedAt,
+		Author:        author,
+		OffsetUnit:    OffsetUnit,
+		Entities:      MapEntitiesToResponse(post.Entities),
+		Media:         MapAttachmentsToResponse(post.Attachments),
+		UserReactions: post.UserReactions,
+		Reactions:     post.Reactions,
+		CommentsCount: post.CommentsCount,
+		IsBookmarked:  post.IsBookmarked,
 	}
 }
 
@@ -45,20 +49,34 @@ func MapEntityToResponse(entity models.PostEntity) *PostEntityInput {
 		return nil
 	}
 
+	return MapEntityToInput(entity.Entity)
+}
+
+func MapEntityToInput(entity models.Entity) *PostEntityInput {
 	return &PostEntityInput{
-		Offset: entity.Offset,
-		Length: entity.Length,
-		Type:   entity.Type,
-		URL:    entity.URL,
+		Offset:   entity.Offset,
+		Length:   entity.Length,
+		Type:     entity.Type,
+		URL:      entity.URL,
+		Language: entity.Language,
+		UserID:   entity.UserID,
+	}
+}
+
+func MapInputToEntity(input PostEntityInput) models.Entity {
+	return models.Entity{
+		Offset:   input.Offset,
+		Length:   input.Length,
+		Type:     input.Type,
+		URL:      input.URL,
+		Language: input.Language,
+		UserID:   input.UserID,
 	}
 }
 
 func MapInputToPostEntity(input PostEntityInput) models.PostEntity {
 	return models.PostEntity{
-		Offset: input.Offset,
-		Length: input.Length,
-		Type:   input.Type,
-		URL:    input.URL,
+		Entity: MapInputToEntity(input),
 	}
 }
 
@@ -69,3 +87,41 @@ func MapInputsToPostEntity(inputs []PostEntityInput) []models.PostEntity {
 	}
 	return entities
 }
+
+func MapAttachmentsToResponse(attachments []models.PostAttachment) []*PostMediaResponse {
+	output := make([]*PostMediaResponse, len(attachments))
+	for i, attachment := range attachments {
+		output[i] = MapAttachmentToResponse(attachment)
+	}
+	return output
+}
+
+func MapAttachmentToResponse(attachment models.PostAttachment) *PostMediaResponse {
+	return &PostMediaResponse{
+		ID:          attachment.MediaID,
+		ContentType: attachment.Media.ContentType,
+		Width:       attachment.Media.Width,
+		Height:      attachment.Media.Height,
+		AltText:     attachment.AltText,
+		Caption:     attachment.Caption,
+
+		BlurHash:      attachment.Media.BlurHash,
+		DominantColor: attachment.Media.DominantColor,
+
+		media: attachment.Media,
+	}
+}
+
+func MapInputsToAttachments(postID uint, inputs []PostMediaInput) []models.PostAttachment {
+	attachments := make([]models.PostAttachment, len(inputs))
+	for i, input := range inputs {
+		attachments[i] = models.PostAttachment{
+			PostID:   postID,
+			MediaID:  input.MediaID,
+			Position: i,
+			AltText:  input.AltText,
+			Caption:  input.Caption,
+		}
+	}
+	return attachments
+}
@@ -0,0 +1,50 @@
+package posts
+
+import (
+	"blog-api/internal/renderer"
+	goerrors "errors"
+	"strings"
+)
+
+const (
+	InputFormatPlain    = "plain"
+	InputFormatMarkdown = "markdown"
+
+	maxURLLength = 500
+)
+
+// ConvertMarkdownInput replaces Markdown content of the input with plain
+// content and the entities parsed from it, with offsets in UTF-16 code units.
+// Plain input is returned as is.
+func ConvertMarkdownInput(input CreatePostInput) (CreatePostInput, error) {
+	if input.InputFormat != InputFormatMarkdown {
+		return input, nil
+	}
+
+	if len(input.Entities) > 0 {
+		return input, goerrors.New("entities cannot be combined with markdown input")
+	}
+
+	content, entities, err := renderer.ParseMarkdown(input.Content)
+	if err != nil {
+		return input, err
+	}
+
+	if strings.TrimSpace(content) == "" {
+		return input, goerrors.New("markdown input produced empty content")
+	}
+
+	inputs := make([]PostEntityInput, len(entities))
+	for i, entity := range entities {
+		if entity.URL != nil && len(*entity.URL) > maxURLLength {
+			return input, goerrors.New("markdown: link url is too long")
+		}
+		inputs[i] = *MapEntityToInput(entity)
+	}
+
+	input.Content = content
+	input.Entities = inputs
+	input.InputFormat = InputFormatPlain
+	input.OffsetUnit = OffsetUnit
+	return input, nil
+}
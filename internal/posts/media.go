@@ -0,0 +1,101 @@
+package posts
+
+import (
+	"blog-api/internal/errors"
+	"blog-api/internal/models"
+	"blog-api/internal/photos"
+	"blog-api/internal/users"
+	"slices"
+
+	"gorm.io/gorm"
+)
+
+// replaceAttachments makes the listed media the attachments of the post, in
+// the given order. Media must be uploaded by the user and must not be
+// attached to another post. Media the post no longer lists is deleted and
+// the keys of the objects no other media shares are returned, to be purged
+// from the storage once the transaction commits.
+func replaceAttachments(tx *gorm.DB, userID, postID uint, inputs []PostMediaInput) ([]string, error) {
+	mediaIDs := make([]uint, len(inputs))
+	for i, input := range inputs {
+		if slices.Contains(mediaIDs[:i], input.MediaID) {
+			return nil, errors.BadRequest("media is attached more than once")
+		}
+		mediaIDs[i] = input.MediaID
+	}
+
+	if len(mediaIDs) > 0 {
+		var owned int64
+		if err := tx.Model(&models.Media{}).
+			Where("id IN ? AND user_id = ?", mediaIDs, userID).
+			Count(&owned).Error; err != nil {
+			return nil, err
+		}
+		if owned != int64(len(mediaIDs)) {
+			return nil, errors.BadRequest("media not found")
+		}
+
+		var taken int64
+		if err := tx.Model(&models.PostAttachment{}).
+			Where("media_id IN ? AND post_id <> ?", mediaIDs, postID).
+			Count(&taken).Error; err != nil {
+			return nil, err
+		}
+		if taken > 0 {
+			return nil, errors.BadRequest("media is already attached to another post")
+		}
+	}
+
+	var existing []models.PostAttachment
+	if err := tx.Preload("Media").Where("post_id = ?", postID).Find(&existing).Error; err != nil {
+		return nil, err
+	}
+
+	if len(existing) > 0 {
+		if err := tx.Where("post_id = ?", postID).Delete(&models.PostAttachment{}).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	if len(inputs) > 0 {
+		attachments := MapInputsToAttachments(postID, inputs)
+		if err := tx.Create(&attachments).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	var (
+		removedIDs  []uint
+		removedKeys []string
+	)
+	for _, attachment := range existing {
+		if !slices.Contains(mediaIDs, attachment.MediaID) {
+			removedIDs = append(removedIDs, attachment.MediaID)
+			removedKeys = append(removedKeys, attachment.Media.Key)
+		}
+	}
+
+	if len(removedIDs) == 0 {
+		return nil, nil
+	}
+	if err := tx.Delete(&models.Media{}, removedIDs).Error; err != nil {
+		return nil, err
+	}
+	return photos.ReleaseObjects(tx, removedKeys)
+}
+
+// setMediaURLs fills the URLs of attached media and their variants and of
+// author avatars, which depend on the storage configuration rather than on
+// the stored rows.
+func (s *postService) setMediaURLs(result ...*PostResponse) {
+	for _, post := range result {
+		if post == nil {
+			continue
+		}
+		users.SetAvatarURLs(s.photoService.MediaURL, post.Author)
+		for _, media := range post.Media {
+			media.URL = s.photoService.MediaURL(media.media.Key)
+			media.Variants = photos.MapVariantsToResponse(media.media.Variants, s.photoService.MediaURL)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package posts
+
+import (
+	"blog-api/internal/errors"
+	"blog-api/internal/models"
+	"blog-api/pkg/textoffset"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ResolveMentions sets the user ID of mentions written as @username and
+// checks that every mentioned user exists. Entities must already have passed
+// ValidateEntities.
+func ResolveMentions(db *gorm.DB, content string, entities []*models.Entity) error {
+	var (
+		usernames []string
+		userIDs   []uint
+	)
+	for _, entity := range entities {
+		if entity.Type != models.EntityMention {
+			continue
+		}
+		if entity.UserID != nil {
+			userIDs = append(userIDs, *entity.UserID)
+			continue
+		}
+		usernames = append(usernames, mentionUsername(content, *entity))
+	}
+
+	if len(usernames) > 0 {
+		resolved, err := GetUserIDsByUsernames(db, usernames)
+		if err != nil {
+			return err
+		}
+
+		for _, entity := range entities {
+			if entity.Type != models.EntityMention || entity.UserID != nil {
+				continue
+			}
+			username := mentionUsername(content, *entity)
+			id, ok := resolved[username]
+			if !ok {
+				return errors.BadRequest("mentioned user not found: @" + username)
+			}
+			entity.UserID = &id
+		}
+	}
+
+	if len(userIDs) > 0 {
+		existing, err := GetExistingUserIDs(db, userIDs)
+		if err != nil {
+			return err
+		}
+
+		for _, id := range userIDs {
+			if !existing[id] {
+				return errors.BadRequest("mentioned user not found")
+			}
+		}
+	}
+	return nil
+}
+
+func mentionUsername(content string, entity models.Entity) string {
+	start, _ := textoffset.ToBytes(content, entity.Offset, textoffset.UTF16)
+	end, _ := textoffset.ToBytes(content, entity.Offset+entity.Length, textoffset.UTF16)
+	return strings.ToLower(strings.TrimPrefix(content[start:end], "@"))
+}
+
+func postEntityRefs(entities []models.PostEntity) []*models.Entity {
+	refs := make([]*models.Entity, len(entities))
+	for i := range entities {
+		refs[i] = &entities[i].Entity
+	}
+	return refs
+}
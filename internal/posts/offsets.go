@@ -0,0 +1,33 @@
+package posts
+
+import (
+	"blog-api/pkg/textoffset"
+	goerrors "errors"
+)
+
+// OffsetUnit is the unit entity offsets are stored and returned in. It
+// matches string indexing in JavaScript, Swift (NSString) and Kotlin.
+const OffsetUnit = string(textoffset.UTF16)
+
+// ConvertEntityOffsets rewrites entity offsets measured in unit to UTF-16
+// code units. An empty unit means the offsets already are UTF-16.
+func ConvertEntityOffsets(content string, entities []PostEntityInput, unit string) ([]PostEntityInput, error) {
+	from := textoffset.Unit(unit)
+	if unit == "" || from == textoffset.UTF16 {
+		return entities, nil
+	}
+	if !from.Valid() {
+		return nil, goerrors.New("unsupported offset unit: " + unit)
+	}
+
+	converted := make([]PostEntityInput, len(entities))
+	for i, entity := range entities {
+		offset, length, err := textoffset.Convert(content, entity.Offset, entity.Length, from, textoffset.UTF16)
+		if err != nil {
+			return nil, goerrors.New("entity range is out of bounds or splits a character")
+		}
+		entity.Offset, entity.Length = offset, length
+		converted[i] = entity
+	}
+	return converted, nil
+}
@@ -1,8 +1,55 @@
 package posts
 
+import (
+	goerrors "errors"
+	"time"
+)
+
+const (
+	OrderByCreatedAt = "created_at"
+	OrderByUpdatedAt = "updated_at"
+	OrderByReactions = "reactions"
+	OrderByReaction  = "reaction"
+)
+
 type FilterParams struct {
-	Limit   int    `query:"limit" validate:"omitempty,min=1,max=100"`
-	Offset  int    `query:"offset" validate:"omitempty,min=0"`
-	Sort    string `query:"sort" validate:"omitempty,oneof=asc desc"`
-	OrderBy string `query:"order_by" validate:"omitempty,oneof=created_at"`
+	Limit     int    `query:"limit" validate:"omitempty,min=1,max=100"`
+	Offset    int    `query:"offset" validate:"omitempty,min=0"`
+	Cursor    string `query:"cursor" validate:"omitempty,max=512,excluded_with=Offset"`
+	WithTotal bool   `query:"with_total"`
+
+	AuthorID    uint   `query:"author_id" validate:"omitempty,min=1"`
+	Author      string `query:"author" validate:"omitempty,max=50"`
+	CreatedFrom string `query:"created_from" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	CreatedTo   string `query:"created_to" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	Reaction    string `query:"reaction" validate:"omitempty,max=50"`
+
+	Sort          string `query:"sort" validate:"omitempty,oneof=asc desc"`
+	OrderBy       string `query:"order_by" validate:"omitempty,oneof=created_at updated_at reactions reaction"`
+	OrderReaction string `query:"order_reaction" validate:"required_if=OrderBy reaction,max=50"`
+
+	Format string `query:"format" validate:"omitempty,oneof=html markdown text"`
+}
+
+type PostParams struct {
+	Format string `query:"format" validate:"omitempty,oneof=html markdown text"`
+}
+
+// CreatedRange returns the [from, to) bounds of the created_at filter.
+// Zero values mean the bound is not set.
+func (p FilterParams) CreatedRange() (from, to time.Time, err error) {
+	if p.CreatedFrom != "" {
+		if from, err = time.Parse(time.RFC3339, p.CreatedFrom); err != nil {
+			return from, to, err
+		}
+	}
+	if p.CreatedTo != "" {
+		if to, err = time.Parse(time.RFC3339, p.CreatedTo); err != nil {
+			return from, to, err
+		}
+	}
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		return from, to, goerrors.New("created_from must be before created_to")
+	}
+	return from, to, nil
 }
@@ -0,0 +1,76 @@
+package posts
+
+import (
+	"blog-api/internal/logger"
+	"blog-api/internal/models"
+	"blog-api/internal/renderer"
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+const (
+	renderCacheKeyPrefix = "post_render"
+	renderCacheTTL       = 24 * time.Hour
+	// renderVersion must be bumped whenever the renderer output changes, so
+	// that cached renderings of unchanged posts are not served any more.
+	renderVersion = 2
+)
+
+// getRenderCacheKey is bound to the renderer version and the post revision,
+// so an edited post never hits a stale entry and old revisions simply expire.
+func (s *postService) getRenderCacheKey(post models.Post, format string) string {
+	return fmt.Sprintf("%s:v%d:%d:%d:%s", renderCacheKeyPrefix, renderVersion, post.ID, post.UpdatedAt.UnixNano(), format)
+}
+
+func PostEntities(post models.Post) []models.Entity {
+	entities := make([]models.Entity, len(post.Entities))
+	for i, entity := range post.Entities {
+		entities[i] = entity.Entity
+	}
+	return entities
+}
+
+// renderPosts fills Rendered of every response with its post content in the
+// requested format. Cache failures are logged and never fail the request.
+func (s *postService) renderPosts(ctx context.Context, log *slog.Logger, posts []models.Post, result []*PostResponse, format string) error {
+	if format == "" || len(posts) == 0 {
+		return nil
+	}
+
+	keys := make([]string, len(posts))
+	for i, post := range posts {
+		keys[i] = s.getRenderCacheKey(post, format)
+	}
+
+	cached, err := s.redis.Client.MGet(ctx, keys...).Result()
+	if err != nil {
+		log.Warn("failed to read render cache", logger.Err(err))
+		cached = make([]any, len(posts))
+	}
+
+	pipe := s.redis.Client.Pipeline()
+	for i, post := range posts {
+		result[i].Format = format
+
+		if v, ok := cached[i].(string); ok {
+			result[i].Rendered = v
+			continue
+		}
+
+		rendered, err := renderer.Render(post.Content, PostEntities(post), format)
+		if err != nil {
+			return err
+		}
+		result[i].Rendered = rendered
+		pipe.Set(ctx, keys[i], rendered, renderCacheTTL)
+	}
+
+	if pipe.Len() > 0 {
+		if _, err := pipe.Exec(ctx); err != nil {
+			log.Warn("failed to write render cache", logger.Err(err))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package posts
+
+import (
+	"blog-api/internal/models"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+func GetCommentCounts(db *gorm.DB, postIDs []uint) (map[uint]int64, error) {
+	var results []struct {
+		PostID uint
+		Count  int64
+	}
+
+	err := db.Model(&models.Comment{}).
+		Select("post_id, COUNT(*) as count").
+		Where("post_id IN ?", postIDs).
+		Group("post_id").
+		Scan(&results).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uint]int64, len(results))
+	for _, r := range results {
+		counts[r.PostID] = r.Count
+	}
+	return counts, nil
+}
+
+func GetBookmarkedPostIDs(db *gorm.DB, postIDs []uint, userID uint) (map[uint]bool, error) {
+	var bookmarked []uint
+
+	err := db.Model(&models.Bookmark{}).
+		Where("user_id = ? AND post_id IN ?", userID, postIDs).
+		Pluck("post_id", &bookmarked).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[uint]bool, len(bookmarked))
+	for _, id := range bookmarked {
+		result[id] = true
+	}
+	return result, nil
+}
+
+func GetUserIDsByUsernames(db *gorm.DB, usernames []string) (map[string]uint, error) {
+	var users []models.User
+
+	err := db.Select("id, username").
+		Where("LOWER(username) IN ?", usernames).
+		Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]uint, len(users))
+	for _, user := range users {
+		result[strings.ToLower(user.Username)] = user.ID
+	}
+	return result, nil
+}
+
+func GetExistingUserIDs(db *gorm.DB, userIDs []uint) (map[uint]bool, error) {
+	var existing []uint
+
+	err := db.Model(&models.User{}).
+		Where("id IN ?", userIDs).
+		Pluck("id", &existing).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[uint]bool, len(existing))
+	for _, id := range existing {
+		result[id] = true
+	}
+	return result, nil
+}
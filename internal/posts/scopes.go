@@ -1,37 +1,152 @@
 package posts
 
 import (
+	"blog-api/internal/reactions"
+	"fmt"
 	"strings"
+	"time"
 
 	"gorm.io/gorm"
-	"gorm.io/gorm/clause"
 )
 
-func OrderScope(orderBy, sort string) func(db *gorm.DB) *gorm.DB {
+const (
+	DefaultLimit = 30
+	MaxLimit     = 100
+)
+
+// orderExprs whitelists the SQL expressions a listing can be sorted by.
+// Column names never come from user input.
+var orderExprs = map[string]string{
+	OrderByCreatedAt: "posts.created_at",
+	OrderByUpdatedAt: "posts.updated_at",
+	OrderByReactions: "COALESCE(rc.count, 0)",
+	OrderByReaction:  "COALESCE(rc.count, 0)",
+}
+
+type Order struct {
+	By       string
+	Reaction string
+	Desc     bool
+}
+
+func NewOrder(orderBy, reaction, sort string) Order {
+	if orderBy == "" {
+		orderBy = OrderByCreatedAt
+	}
+	if orderBy != OrderByReaction {
+		reaction = ""
+	}
+
+	return Order{
+		By:       orderBy,
+		Reaction: reaction,
+		Desc:     strings.ToLower(sort) == "desc",
+	}
+}
+
+func (o Order) Valid() bool {
+	_, ok := orderExprs[o.By]
+	return ok && (o.By != OrderByReaction || o.Reaction != "")
+}
+
+func (o Order) countsReactions() bool {
+	return o.By == OrderByReactions || o.By == OrderByReaction
+}
+
+func NormalizeLimit(limit int) int {
+	switch {
+	case limit > MaxLimit:
+		return MaxLimit
+	case limit <= 0:
+		return DefaultLimit
+	}
+	return limit
+}
+
+func FilterScope(params FilterParams, createdFrom, createdTo time.Time) func(db *gorm.DB) *gorm.DB {
 	return func(db *gorm.DB) *gorm.DB {
-		if orderBy == "" {
-			orderBy = "created_at"
+		if params.AuthorID != 0 {
+			db = db.Where("posts.author_id = ?", params.AuthorID)
+		}
+		if params.Author != "" {
+			db = db.Where("posts.author_id IN (SELECT id FROM users WHERE LOWER(username) = LOWER(?))", params.Author)
 		}
+		if !createdFrom.IsZero() {
+			db = db.Where("posts.created_at >= ?", createdFrom)
+		}
+		if !createdTo.IsZero() {
+			db = db.Where("posts.created_at < ?", createdTo)
+		}
+		if params.Reaction != "" {
+			db = db.Where(`EXISTS (
+				SELECT 1 FROM reaction_counts rc
+				JOIN reaction_types rt ON rt.id = rc.reaction_type_id
+				WHERE rc.target_type = ? AND rc.target_id = posts.id AND rc.count > 0 AND rt.name = ?
+			)`, reactions.TargetPost, params.Reaction)
+		}
+		return db
+	}
+}
 
-		desc := strings.ToLower(sort) == "desc"
-		return db.Order(clause.OrderByColumn{Column: clause.Column{Name: orderBy}, Desc: desc})
+func OrderScope(order Order) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return sortBy(db, order, order.Desc)
 	}
 }
 
-func PaginationScope(limit, offset int) func(db *gorm.DB) *gorm.DB {
+// KeysetScope selects the rows that follow the cursor position. When paging
+// backward the comparison and the order are flipped, so the caller has to
+// reverse the fetched rows.
+func KeysetScope(c Cursor) func(db *gorm.DB) *gorm.DB {
 	return func(db *gorm.DB) *gorm.DB {
-		switch {
-		case limit > 100:
-			limit = 100
-		case limit <= 0:
-			limit = 30
+		order := c.Order()
+		desc := c.Desc != c.Backward
+
+		op := ">"
+		if desc {
+			op = "<"
 		}
 
-		db.Limit(limit)
+		db = sortBy(db, order, desc)
+		return db.Where(fmt.Sprintf("(%s, posts.id) %s (?, ?)", orderExprs[order.By], op), c.value(), c.ID)
+	}
+}
+
+func sortBy(db *gorm.DB, order Order, desc bool) *gorm.DB {
+	if order.countsReactions() {
+		db = db.Select("posts.*, COALESCE(rc.count, 0) AS reaction_count").
+			Joins("LEFT JOIN (?) rc ON rc.target_id = posts.id", reactionCounts(db, order.Reaction))
+	}
 
-		if offset > 0 {
-			db = db.Offset(offset)
-		}
-		return db
+	dir := "ASC"
+	if desc {
+		dir = "DESC"
+	}
+	return db.Order(fmt.Sprintf("%s %s, posts.id %s", orderExprs[order.By], dir, dir))
+}
+
+func reactionCounts(db *gorm.DB, reaction string) *gorm.DB {
+	q := db.Session(&gorm.Session{NewDB: true}).
+		Table("reaction_counts rc").
+		Select("rc.target_id, SUM(rc.count)::bigint AS count").
+		Where("rc.target_type = ?", reactions.TargetPost).
+		Group("rc.target_id")
+
+	if reaction != "" {
+		q = q.Joins("JOIN reaction_types rt ON rt.id = rc.reaction_type_id").
+			Where("rt.name = ?", reaction)
 	}
+	return q
+}
+
+// PreloadScope loads everything a post response is built from.
+func PreloadScope(db *gorm.DB) *gorm.DB {
+	return db.Preload("Author").
+		Preload("Entities").
+		Preload("Attachments", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position")
+		}).
+		Preload("Attachments.Media.Variants", func(db *gorm.DB) *gorm.DB {
+			return db.Order("size")
+		})
 }
@@ -5,31 +5,39 @@ import (
 	"blog-api/internal/errors"
 	"blog-api/internal/logger"
 	"blog-api/internal/models"
+	"blog-api/internal/photos"
 	"blog-api/internal/reactions"
+	"blog-api/internal/storage"
 	"context"
 	goerrors "errors"
 	"log/slog"
+	"slices"
 
 	"gorm.io/gorm"
 )
 
 type IPostService interface {
 	CreatePost(ctx context.Context, userID uint, input CreatePostInput) (*PostResponse, error)
-	GetPost(ctx context.Context, postID uint, userID *uint) (*PostResponse, error)
+	GetPost(ctx context.Context, postID uint, userID *uint, params PostParams) (*PostResponse, error)
 	GetPosts(ctx context.Context, params FilterParams, userID *uint) (*ListResponse, error)
-	UpdatePost(ctx context.Context, userID uint, postID uint, input CreatePostInput) (*PostResponse, error)
+	GetPostsByIDs(ctx context.Context, postIDs []uint, userID *uint) ([]*PostResponse, error)
+	UpdatePost(ctx context.Context, userID uint, postID uint, input UpdatePostInput) (*PostResponse, error)
 	DeletePost(ctx context.Context, userID uint, postID uint) error
 }
 
 type postService struct {
-	db     *database.DB
-	logger *slog.Logger
+	db           *database.DB
+	redis        *storage.RedisClient
+	photoService photos.IPhotoService
+	logger       *slog.Logger
 }
 
-func NewPostService(db *database.DB, logger *slog.Logger) IPostService {
+func NewPostService(db *database.DB, redis *storage.RedisClient, photoService photos.IPhotoService, logger *slog.Logger) IPostService {
 	return &postService{
-		db:     db,
-		logger: logger,
+		db:           db,
+		redis:        redis,
+		photoService: photoService,
+		logger:       logger,
 	}
 }
 
@@ -40,10 +48,23 @@ func (s *postService) CreatePost(ctx context.Context, userID uint, input CreateP
 	log.Info("creating new post")
 	log.Debug("post input data", slog.Any("input", input))
 
+	input, err := ConvertMarkdownInput(input)
+	if err != nil {
+		log.Warn("markdown conversion failed", logger.Err(err))
+		return nil, errors.BadRequest(err.Error())
+	}
+
+	input.Entities, err = ConvertEntityOffsets(input.Content, input.Entities, input.OffsetUnit)
+	if err != nil {
+		log.Warn("entity offset conversion failed", logger.Err(err))
+		return nil, errors.BadRequest(err.Error())
+	}
+
 	post := models.Post{
-		Title:    input.Title,
-		Content:  input.Content,
-		AuthorID: userID,
+		Title:      input.Title,
+		Content:    input.Content,
+		AuthorID:   userID,
+		OffsetUnit: OffsetUnit,
 	}
 
 	entities := MapInputsToPostEntity(input.Entities)
@@ -53,22 +74,40 @@ func (s *postService) CreatePost(ctx context.Context, userID uint, input CreateP
 		return nil, errors.BadRequest(err.Error())
 	}
 
-	if err := db.Create(&post).Error; err != nil {
-		log.Error("failed to create post in database", logger.Err(err))
+	if err := ResolveMentions(db, post.Content, postEntityRefs(post.Entities)); err != nil {
+		log.Warn("failed to resolve mentions", logger.Err(err))
+		return nil, err
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&post).Error; err != nil {
+			log.Error("failed to create post in database", logger.Err(err))
+			return err
+		}
+
+		if _, err := replaceAttachments(tx, userID, post.ID, input.Media); err != nil {
+			log.Warn("failed to attach media", logger.Err(err))
+			return err
+		}
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	if err := db.Preload("Author").Preload("Entities").First(&post, post.ID).Error; err != nil {
+	if err := db.Scopes(PreloadScope).First(&post, post.ID).Error; err != nil {
 		log.Error("failed to fetch post from database", logger.Err(err))
 		return nil, err
 	}
 
 	log.Info("post created successfully", slog.Uint64("post_id", uint64(post.ID)))
 
-	return MapPostToResponse(post), nil
+	result := MapPostToResponse(post)
+	s.setMediaURLs(result)
+	return result, nil
 }
 
-func (s *postService) GetPost(ctx context.Context, postID uint, userID *uint) (*PostResponse, error) {
+func (s *postService) GetPost(ctx context.Context, postID uint, userID *uint, params PostParams) (*PostResponse, error) {
 	db := s.db.WithContext(ctx)
 	log := logger.FromCtx(ctx, s.logger).With(slog.Uint64("post_id", uint64(postID)))
 
@@ -76,7 +115,7 @@ func (s *postService) GetPost(ctx context.Context, postID uint, userID *uint) (*
 
 	var post models.Post
 
-	err := db.Preload("Author").Preload("Entities").First(&post, postID).Error
+	err := db.Scopes(PreloadScope).First(&post, postID).Error
 	if err != nil {
 		if goerrors.Is(err, gorm.ErrRecordNotFound) {
 			log.Warn("post not found")
@@ -87,27 +126,22 @@ func (s *postService) GetPost(ctx context.Context, postID uint, userID *uint) (*
 		return nil, err
 	}
 
-	aggReact, err := reactions.GetReactionsAggregate(db, "posts", []uint{postID})
-	if err != nil {
-		log.Error("failed to aggregate reactions", logger.Err(err))
+	posts := []models.Post{post}
+	if err := s.loadStats(db, posts, userID); err != nil {
+		log.Error("failed to load post stats", logger.Err(err))
 		return nil, err
 	}
-	post.Reactions = aggReact[postID]
 
-	if userID != nil && len(post.Reactions) > 0 {
-		userReact, err := reactions.GetUserReactions(db, "posts", []uint{post.ID}, *userID)
-		if err != nil {
-			log.Error("failed to get user reactions", logger.Err(err))
-			return nil, err
-		}
-		if userReaction, ok := userReact[post.ID]; ok {
-			post.UserReaction = userReaction
-		}
+	result := MapPostsToResponse(posts)
+	s.setMediaURLs(result...)
+	if err := s.renderPosts(ctx, log, posts, result, params.Format); err != nil {
+		log.Error("failed to render post", logger.Err(err))
+		return nil, err
 	}
 
 	log.Info("post retrieved successfully")
 
-	return MapPostToResponse(post), nil
+	return result[0], nil
 }
 
 func (s *postService) GetPosts(ctx context.Context, params FilterParams, userID *uint) (*ListResponse, error) {
@@ -117,77 +151,177 @@ func (s *postService) GetPosts(ctx context.Context, params FilterParams, userID
 	log.Info("fetching posts list")
 	log.Debug("filter parameters", slog.Any("params", params))
 
-	var posts []models.Post
-
-	query := db.Preload("Author").Preload("Entities").
-		Scopes(
-			OrderScope(params.OrderBy, params.Sort),
-			PaginationScope(params.Limit, params.Offset),
-		)
+	var (
+		c         Cursor
+		hasCursor = params.Cursor != ""
+	)
+	if hasCursor {
+		decoded, err := DecodeCursor(params.Cursor)
+		if err != nil {
+			log.Warn("failed to decode cursor", logger.Err(err))
+			return nil, errors.ErrInvalidCursor
+		}
+		c = decoded
+	}
 
-	err := query.Find(&posts).Error
+	createdFrom, createdTo, err := params.CreatedRange()
 	if err != nil {
-		log.Error("failed to fetch posts from database", logger.Err(err))
-		return nil, err
+		log.Warn("invalid created_at range", logger.Err(err))
+		return nil, errors.BadRequest(err.Error())
 	}
 
-	var total int64
-	if err := db.Model(&posts).Count(&total).Error; err != nil {
-		log.Error("failed to count posts", logger.Err(err))
-		return nil, err
+	limit := NormalizeLimit(params.Limit)
+	order := NewOrder(params.OrderBy, params.OrderReaction, params.Sort)
+	if hasCursor {
+		order = c.Order()
+	}
+	if !order.Valid() {
+		log.Warn("invalid order", slog.String("order_by", order.By), slog.Bool("from_cursor", hasCursor))
+		if hasCursor {
+			return nil, errors.ErrInvalidCursor
+		}
+		return nil, errors.ErrInvalidQuery
 	}
 
-	postIDs := make([]uint, len(posts))
-	for i, p := range posts {
-		postIDs[i] = p.ID
+	var posts []models.Post
+
+	// one extra row tells whether there is another page in the requested direction
+	query := db.Scopes(PreloadScope).
+		Scopes(FilterScope(params, createdFrom, createdTo)).
+		Limit(limit + 1)
+	if hasCursor {
+		query = query.Scopes(KeysetScope(c))
+	} else {
+		query = query.Scopes(OrderScope(order)).Offset(params.Offset)
 	}
 
-	aggReact, err := reactions.GetReactionsAggregate(db, "posts", postIDs)
-	if err != nil {
-		log.Error("failed to aggregate reactions", logger.Err(err))
+	if err := query.Find(&posts).Error; err != nil {
+		log.Error("failed to fetch posts from database", logger.Err(err))
 		return nil, err
 	}
 
-	var userReact map[uint]*models.UserReaction
-	if userID != nil && len(aggReact) > 0 {
-		userReact, err = reactions.GetUserReactions(db, "posts", postIDs, *userID)
+	hasMore := len(posts) > limit
+	if hasMore {
+		posts = posts[:limit]
+	}
+	if c.Backward {
+		slices.Reverse(posts)
+	}
+
+	var total *int64
+	if params.WithTotal {
+		var count int64
+		err := db.Model(&models.Post{}).
+			Scopes(FilterScope(params, createdFrom, createdTo)).
+			Count(&count).Error
 		if err != nil {
-			log.Error("failed to get user reactions", logger.Err(err))
+			log.Error("failed to count posts", logger.Err(err))
 			return nil, err
 		}
+		total = &count
 	}
 
-	for i := range posts {
-		currPost := &posts[i]
-		currPost.Reactions = aggReact[currPost.ID]
-		if ur, ok := userReact[currPost.ID]; ok {
-			currPost.UserReaction = ur
+	listResponse := &ListResponse{Total: total}
+	if len(posts) > 0 {
+		hasNext := hasMore || c.Backward
+		hasPrev := (hasCursor && !c.Backward) || (c.Backward && hasMore) || params.Offset > 0
+
+		if hasNext {
+			if listResponse.NextCursor, err = EncodeCursor(NewCursor(posts[len(posts)-1], order, false)); err != nil {
+				log.Error("failed to encode next cursor", logger.Err(err))
+				return nil, err
+			}
+		}
+		if hasPrev {
+			if listResponse.PrevCursor, err = EncodeCursor(NewCursor(posts[0], order, true)); err != nil {
+				log.Error("failed to encode prev cursor", logger.Err(err))
+				return nil, err
+			}
 		}
 	}
 
-	result := MapPostsToResponse(posts)
-	listResponse := &ListResponse{
-		Total:  total,
-		Result: result,
+	if err := s.loadStats(db, posts, userID); err != nil {
+		log.Error("failed to load post stats", logger.Err(err))
+		return nil, err
+	}
+
+	listResponse.Result = MapPostsToResponse(posts)
+	s.setMediaURLs(listResponse.Result...)
+	if err := s.renderPosts(ctx, log, posts, listResponse.Result, params.Format); err != nil {
+		log.Error("failed to render posts", logger.Err(err))
+		return nil, err
 	}
 
 	log.Info("posts retrieved successfully",
-		slog.Int64("total", total),
 		slog.Int("returned", len(posts)),
+		slog.Bool("has_more", hasMore),
 		slog.Bool("authenticated", userID != nil),
 	)
 
 	return listResponse, nil
 }
 
-func (s *postService) UpdatePost(ctx context.Context, userID uint, postID uint, input CreatePostInput) (*PostResponse, error) {
+func (s *postService) GetPostsByIDs(ctx context.Context, postIDs []uint, userID *uint) ([]*PostResponse, error) {
+	db := s.db.WithContext(ctx)
+	log := logger.FromCtx(ctx, s.logger)
+
+	log.Info("fetching posts by ids", slog.Int("count", len(postIDs)))
+
+	if len(postIDs) == 0 {
+		return []*PostResponse{}, nil
+	}
+
+	var found []models.Post
+	if err := db.Scopes(PreloadScope).Find(&found, postIDs).Error; err != nil {
+		log.Error("failed to fetch posts from database", logger.Err(err))
+		return nil, err
+	}
+
+	// keep the order of the requested ids, skipping posts deleted in the meantime
+	byID := make(map[uint]models.Post, len(found))
+	for _, p := range found {
+		byID[p.ID] = p
+	}
+
+	posts := make([]models.Post, 0, len(found))
+	for _, id := range postIDs {
+		if p, ok := byID[id]; ok {
+			posts = append(posts, p)
+		}
+	}
+
+	if err := s.loadStats(db, posts, userID); err != nil {
+		log.Error("failed to load post stats", logger.Err(err))
+		return nil, err
+	}
+
+	result := MapPostsToResponse(posts)
+	s.setMediaURLs(result...)
+	return result, nil
+}
+
+func (s *postService) UpdatePost(ctx context.Context, userID uint, postID uint, input UpdatePostInput) (*PostResponse, error) {
 	db := s.db.WithContext(ctx)
 	log := logger.WithUserID(logger.FromCtx(ctx, s.logger), userID).With(slog.Uint64("post_id", uint64(postID)))
 
 	log.Info("updating post")
 	log.Debug("update input data", slog.Any("input", input))
 
-	err := db.Transaction(func(tx *gorm.DB) error {
+	converted, err := ConvertMarkdownInput(input.CreatePostInput)
+	if err != nil {
+		log.Warn("markdown conversion failed", logger.Err(err))
+		return nil, errors.BadRequest(err.Error())
+	}
+	input.CreatePostInput = converted
+
+	input.Entities, err = ConvertEntityOffsets(input.Content, input.Entities, input.OffsetUnit)
+	if err != nil {
+		log.Warn("entity offset conversion failed", logger.Err(err))
+		return nil, errors.BadRequest(err.Error())
+	}
+
+	var removedKeys []string
+	err = db.Transaction(func(tx *gorm.DB) error {
 		var post models.Post
 		if err := tx.Preload("Author").First(&post, postID).Error; err != nil {
 			if goerrors.Is(err, database.ErrRecordNotFound) {
@@ -208,8 +342,9 @@ func (s *postService) UpdatePost(ctx context.Context, userID uint, postID uint,
 
 		log.Debug("updating post fields")
 		if err := tx.Model(&post).Updates(models.Post{
-			Title:   input.Title,
-			Content: input.Content,
+			Title:      input.Title,
+			Content:    input.Content,
+			OffsetUnit: OffsetUnit,
 		}).Error; err != nil {
 			log.Error("failed to update post fields", logger.Err(err))
 			return err
@@ -223,6 +358,7 @@ func (s *postService) UpdatePost(ctx context.Context, userID uint, postID uint,
 
 		if len(input.Entities) > 0 {
 			entities := MapInputsToPostEntity(input.Entities)
+			post.Content = input.Content
 			post.Entities = entities
 
 			if err := ValidatePostEntities(post); err != nil {
@@ -230,6 +366,11 @@ func (s *postService) UpdatePost(ctx context.Context, userID uint, postID uint,
 				return errors.BadRequest(err.Error())
 			}
 
+			if err := ResolveMentions(tx, post.Content, postEntityRefs(entities)); err != nil {
+				log.Warn("failed to resolve mentions", logger.Err(err))
+				return err
+			}
+
 			for i := range entities {
 				entities[i].PostID = post.ID
 			}
@@ -241,6 +382,18 @@ func (s *postService) UpdatePost(ctx context.Context, userID uint, postID uint,
 			}
 		}
 
+		if input.Media == nil {
+			return nil
+		}
+
+		log.Debug("replacing attachments")
+		keys, err := replaceAttachments(tx, userID, post.ID, *input.Media)
+		if err != nil {
+			log.Warn("failed to attach media", logger.Err(err))
+			return err
+		}
+		removedKeys = keys
+
 		return nil
 	})
 
@@ -249,15 +402,19 @@ func (s *postService) UpdatePost(ctx context.Context, userID uint, postID uint,
 		return nil, err
 	}
 
+	s.photoService.PurgeObjects(ctx, removedKeys)
+
 	var updatedPost models.Post
-	if err = db.Preload("Author").Preload("Entities").First(&updatedPost, postID).Error; err != nil {
+	if err = db.Scopes(PreloadScope).First(&updatedPost, postID).Error; err != nil {
 		log.Error("failed to load updated post", logger.Err(err))
 		return nil, err
 	}
 
 	log.Info("post updated successfully")
 
-	return MapPostToResponse(updatedPost), nil
+	result := MapPostToResponse(updatedPost)
+	s.setMediaURLs(result)
+	return result, nil
 }
 
 func (s *postService) DeletePost(ctx context.Context, userID uint, postID uint) error {
@@ -284,12 +441,80 @@ func (s *postService) DeletePost(ctx context.Context, userID uint, postID uint)
 		return errors.ErrForbidden
 	}
 
-	if err := db.Unscoped().Select("Entities").Delete(&post).Error; err != nil {
-		log.Error("failed to delete post", logger.Err(err))
+	var removedKeys []string
+	err := db.Transaction(func(tx *gorm.DB) error {
+		keys, err := replaceAttachments(tx, userID, post.ID, nil)
+		if err != nil {
+			log.Error("failed to delete post media", logger.Err(err))
+			return err
+		}
+		removedKeys = keys
+
+		if err := tx.Unscoped().Select("Entities").Delete(&post).Error; err != nil {
+			log.Error("failed to delete post", logger.Err(err))
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.photoService.PurgeObjects(ctx, removedKeys)
+
+	log.Info("post deleted successfully", slog.Int("objects_purged", len(removedKeys)))
+
+	return nil
+}
+
+// loadStats fills reactions and comment counts of the given posts in place.
+func (s *postService) loadStats(db *gorm.DB, posts []models.Post, userID *uint) error {
+	if len(posts) == 0 {
+		return nil
+	}
+
+	postIDs := make([]uint, len(posts))
+	for i, p := range posts {
+		postIDs[i] = p.ID
+	}
+
+	aggReact, err := reactions.GetReactionsAggregate(db, reactions.TargetPost, postIDs)
+	if err != nil {
 		return err
 	}
 
-	log.Info("post deleted successfully")
+	commentCounts, err := GetCommentCounts(db, postIDs)
+	if err != nil {
+		return err
+	}
+
+	var (
+		userReact  map[uint][]models.UserReaction
+		bookmarked map[uint]bool
+	)
+	if userID != nil {
+		if len(aggReact) > 0 {
+			userReact, err = reactions.GetUserReactions(db, reactions.TargetPost, postIDs, *userID)
+			if err != nil {
+				return err
+			}
+		}
+
+		bookmarked, err = GetBookmarkedPostIDs(db, postIDs, *userID)
+		if err != nil {
+			return err
+		}
+	}
 
+	for i := range posts {
+		currPost := &posts[i]
+		currPost.Reactions = aggReact[currPost.ID]
+		currPost.CommentsCount = commentCounts[currPost.ID]
+		currPost.UserReactions = userReact[currPost.ID]
+		if userID != nil {
+			isBookmarked := bookmarked[currPost.ID]
+			currPost.IsBookmarked = &isBookmarked
+		}
+	}
 	return nil
 }
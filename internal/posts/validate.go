@@ -2,22 +2,38 @@ package posts
 
 import (
 	"blog-api/internal/models"
+	"blog-api/internal/renderer"
+	"blog-api/internal/validator"
+	"blog-api/pkg/textoffset"
 	goerrors "errors"
+	"fmt"
+	"regexp"
+	"strings"
 )
 
+var hashtagRe = regexp.MustCompile(`^#[\p{L}\p{N}][\p{L}\p{N}_]*$`)
+
 func ValidatePostEntities(post models.Post) error {
-	contentLength := len(post.Content)
-	entities := post.Entities
+	return ValidateEntities(post.Content, PostEntities(post))
+}
 
+// ValidateEntities checks entities whose offsets are measured in UTF-16 code
+// units. A range may not end outside the content or split a surrogate pair,
+// each type only accepts its own fields and text, and code, blocks, links,
+// mentions and hashtags only nest in the ways the renderer can represent.
+func ValidateEntities(content string, entities []models.Entity) error {
 	for i, entity := range entities {
-		if entity.Type == "link" && entity.URL == nil {
-			return goerrors.New("type link: url is required")
+		start, ok := textoffset.ToBytes(content, entity.Offset, textoffset.UTF16)
+		end, endOk := textoffset.ToBytes(content, entity.Offset+entity.Length, textoffset.UTF16)
+		if !ok || !endOk {
+			return goerrors.New("entity range is out of bounds or splits a character")
 		}
-		if entity.Type != "link" && entity.URL != nil {
-			return goerrors.New("url should only be provided for link type")
+
+		if err := validateEntityFields(entity); err != nil {
+			return err
 		}
-		if entity.Offset+entity.Length > contentLength {
-			return goerrors.New("entity range is out of bounds")
+		if err := validateEntityText(entity, content, start, end); err != nil {
+			return err
 		}
 
 		for j := i + 1; j < len(entities); j++ {
@@ -25,7 +41,122 @@ func ValidatePostEntities(post models.Post) error {
 			if entity.Offset == otherEntity.Offset && entity.Length == otherEntity.Length && entity.Type == otherEntity.Type {
 				return goerrors.New("duplicate entity detected: type=" + entity.Type)
 			}
+			if err := validateEntityNesting(entity, otherEntity); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func validateEntityFields(entity models.Entity) error {
+	if entity.Type == models.EntityLink && entity.URL == nil {
+		return goerrors.New("type link: url is required")
+	}
+	if entity.Type != models.EntityLink && entity.URL != nil {
+		return goerrors.New("url should only be provided for link type")
+	}
+	if entity.Type != models.EntityPre && entity.Language != nil {
+		return goerrors.New("language should only be provided for pre type")
+	}
+	if entity.Language != nil && !renderer.IsLanguage(*entity.Language) {
+		return goerrors.New("type pre: invalid language")
+	}
+	if entity.Type != models.EntityMention && entity.UserID != nil {
+		return goerrors.New("user_id should only be provided for mention type")
+	}
+	return nil
+}
+
+func validateEntityText(entity models.Entity, content string, start, end int) error {
+	text := content[start:end]
+
+	switch entity.Type {
+	case models.EntityCode:
+		if strings.Contains(text, "\n") {
+			return goerrors.New("type code: cannot span lines, use pre")
+		}
+	case models.EntityPre, models.EntityBlockquote:
+		if start > 0 && content[start-1] != '\n' || end < len(content) && content[end] != '\n' {
+			return fmt.Errorf("type %s: must cover whole lines", entity.Type)
+		}
+	case models.EntityHashtag:
+		if !hashtagRe.MatchString(text) {
+			return goerrors.New("type hashtag: text must be a #tag")
+		}
+	case models.EntityMention:
+		// a mention with a user_id may carry any text, e.g. a display name
+		if entity.UserID == nil && (!strings.HasPrefix(text, "@") || !validator.IsUsername(text[1:])) {
+			return goerrors.New("type mention: text must be an @username unless user_id is provided")
 		}
 	}
 	return nil
 }
+
+func validateEntityNesting(a, b models.Entity) error {
+	aEnd, bEnd := a.Offset+a.Length, b.Offset+b.Length
+
+	switch {
+	case aEnd <= b.Offset || bEnd <= a.Offset:
+		return nil
+	case a.Offset == b.Offset && aEnd == bEnd:
+		if canContain(a, b) || canContain(b, a) {
+			return nil
+		}
+	case a.Offset <= b.Offset && bEnd <= aEnd:
+		if canContain(a, b) {
+			return nil
+		}
+	case b.Offset <= a.Offset && aEnd <= bEnd:
+		if canContain(b, a) {
+			return nil
+		}
+	default:
+		if canOverlap(a) && canOverlap(b) && !(a.Type == models.EntityLink && b.Type == models.EntityLink) {
+			return nil
+		}
+		return fmt.Errorf("entities %s and %s cannot partially overlap", a.Type, b.Type)
+	}
+	return fmt.Errorf("entity %s cannot be nested in %s", innerType(a, b), outerType(a, b))
+}
+
+// canContain reports whether inner may be nested in outer. Code keeps its text
+// verbatim, blocks can only be top level and links, mentions and hashtags are
+// all clickable, so none of them can hold another.
+func canContain(outer, inner models.Entity) bool {
+	switch {
+	case outer.Type == models.EntityCode || outer.Type == models.EntityPre:
+		return false
+	case inner.Type == models.EntityPre || inner.Type == models.EntityBlockquote:
+		return false
+	case isClickable(outer) && isClickable(inner):
+		return false
+	}
+	return true
+}
+
+func canOverlap(e models.Entity) bool {
+	switch e.Type {
+	case models.EntityCode, models.EntityPre, models.EntityBlockquote, models.EntityMention, models.EntityHashtag:
+		return false
+	}
+	return true
+}
+
+func isClickable(e models.Entity) bool {
+	return e.Type == models.EntityLink || e.Type == models.EntityMention || e.Type == models.EntityHashtag
+}
+
+func innerType(a, b models.Entity) string {
+	if a.Length < b.Length {
+		return a.Type
+	}
+	return b.Type
+}
+
+func outerType(a, b models.Entity) string {
+	if a.Length < b.Length {
+		return b.Type
+	}
+	return a.Type
+}
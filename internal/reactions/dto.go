@@ -1,6 +1,10 @@
 package reactions
 
-import "blog-api/internal/models"
+import (
+	"blog-api/internal/models"
+	"blog-api/internal/users"
+	"time"
+)
 
 type SetReactionInput struct {
 	TargetType string `json:"target_type" validate:"required"`
@@ -8,16 +12,26 @@ type SetReactionInput struct {
 	ReactionID uint   `json:"reaction_id" validate:"required"`
 }
 
+// ReactionInput is the body of reactions to a target given by the URL.
+type ReactionInput struct {
+	ReactionID uint `json:"reaction_id" validate:"required"`
+}
+
 type SetPostReactionInput struct {
 	PostID     uint `json:"post_id" validate:"required"`
 	ReactionID uint `json:"reaction_id" validate:"required"`
 }
 
+type SetCommentReactionInput struct {
+	CommentID  uint `json:"comment_id" validate:"required"`
+	ReactionID uint `json:"reaction_id" validate:"required"`
+}
+
 type ReactionResponse struct {
-	UserID       uint                 `json:"user_id"`
-	TargetType   string               `json:"target_type"`
-	TargetID     uint                 `json:"target_id"`
-	UserReaction *models.UserReaction `json:"user_reaction"`
+	UserID        uint                  `json:"user_id,omitempty"`
+	TargetType    string                `json:"target_type"`
+	TargetID      uint                  `json:"target_id"`
+	UserReactions []models.UserReaction `json:"user_reactions"`
 
 	Reactions []models.ReactionStat `json:"reactions"`
 }
@@ -27,4 +41,40 @@ type ReactionTypeResponse struct {
 	Type       string `json:"type"`
 	Icon       string `json:"icon"`
 	IsActive   bool   `json:"is_active"`
+	SortOrder  int    `json:"sort_order"`
+}
+
+type CreateReactionTypeInput struct {
+	Name     string `json:"name" validate:"required,max=50"`
+	Icon     string `json:"icon" validate:"required,max=50"`
+	IsActive *bool  `json:"is_active"`
+}
+
+// UpdateReactionTypeInput changes the fields that are set.
+type UpdateReactionTypeInput struct {
+	Name     *string `json:"name" validate:"omitempty,min=1,max=50"`
+	Icon     *string `json:"icon" validate:"omitempty,min=1,max=50"`
+	IsActive *bool   `json:"is_active"`
+}
+
+// ReorderReactionTypesInput lists every reaction type, in the new order.
+type ReorderReactionTypesInput struct {
+	ReactionIDs []uint `json:"reaction_ids" validate:"required,min=1,unique,dive,required"`
+}
+
+type ReactorsParams struct {
+	Type   string `query:"type" validate:"omitempty,max=50"`
+	Limit  int    `query:"limit" validate:"omitempty,min=1,max=100"`
+	Cursor string `query:"cursor" validate:"omitempty,max=512"`
+}
+
+type ReactorResponse struct {
+	User      *users.UserResponse  `json:"user"`
+	Reaction  *models.UserReaction `json:"reaction"`
+	ReactedAt time.Time            `json:"reacted_at"`
+}
+
+type ReactorsResponse struct {
+	NextCursor string             `json:"next_cursor,omitempty"`
+	Result     []*ReactorResponse `json:"result"`
 }
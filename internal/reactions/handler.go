@@ -1,6 +1,7 @@
 package reactions
 
 import (
+	"blog-api/internal/errors"
 	"blog-api/internal/logger"
 	"blog-api/internal/users"
 	"blog-api/pkg/response"
@@ -11,8 +12,17 @@ import (
 )
 
 type IReactionHandler interface {
+	SetReaction(ctx fiber.Ctx) error
+	GetReactions(ctx fiber.Ctx) error
+	GetReactors(ctx fiber.Ctx) error
 	SetPostReaction(ctx fiber.Ctx) error
+	SetCommentReaction(ctx fiber.Ctx) error
 	GetAvailableReactions(ctx fiber.Ctx) error
+
+	ListReactionTypes(ctx fiber.Ctx) error
+	CreateReactionType(ctx fiber.Ctx) error
+	UpdateReactionType(ctx fiber.Ctx) error
+	ReorderReactionTypes(ctx fiber.Ctx) error
 }
 
 type reactionHandler struct {
@@ -25,6 +35,60 @@ func NewReactionHandler(reactionService IReactionService) IReactionHandler {
 	}
 }
 
+func (h *reactionHandler) SetReaction(ctx fiber.Ctx) error {
+	user := users.MustGetUser(ctx)
+	targetType := ctx.Params("target_type")
+	targetID := fiber.Params[uint](ctx, "id")
+
+	requestID := requestid.FromContext(ctx)
+
+	var input ReactionInput
+	if err := ctx.Bind().Body(&input); err != nil {
+		return err
+	}
+
+	res, err := h.reactionService.SetReaction(
+		context.WithValue(ctx, logger.RequestIDKey, requestID),
+		user.UserID,
+		SetReactionInput{
+			TargetType: targetType,
+			TargetID:   targetID,
+			ReactionID: input.ReactionID,
+		},
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(response.NewResponse(res))
+}
+
+func (h *reactionHandler) GetReactions(ctx fiber.Ctx) error {
+	user := users.GetUser(ctx)
+	var userID *uint
+	if user != nil {
+		userID = &user.UserID
+	}
+	targetType := ctx.Params("target_type")
+	targetID := fiber.Params[uint](ctx, "id")
+
+	requestID := requestid.FromContext(ctx)
+
+	res, err := h.reactionService.GetReactions(
+		context.WithValue(ctx, logger.RequestIDKey, requestID),
+		targetType,
+		targetID,
+		userID,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(response.NewResponse(res))
+}
+
 func (h *reactionHandler) SetPostReaction(ctx fiber.Ctx) error {
 	user := users.MustGetUser(ctx)
 
@@ -49,6 +113,30 @@ func (h *reactionHandler) SetPostReaction(ctx fiber.Ctx) error {
 	return ctx.JSON(response.NewResponse(res))
 }
 
+func (h *reactionHandler) SetCommentReaction(ctx fiber.Ctx) error {
+	user := users.MustGetUser(ctx)
+
+	requestID := requestid.FromContext(ctx)
+
+	var input SetCommentReactionInput
+
+	if err := ctx.Bind().Body(&input); err != nil {
+		return err
+	}
+
+	res, err := h.reactionService.SetCommentReaction(
+		context.WithValue(ctx, logger.RequestIDKey, requestID),
+		user.UserID,
+		input,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(response.NewResponse(res))
+}
+
 func (h *reactionHandler) GetAvailableReactions(ctx fiber.Ctx) error {
 	requestID := requestid.FromContext(ctx)
 
@@ -59,3 +147,101 @@ func (h *reactionHandler) GetAvailableReactions(ctx fiber.Ctx) error {
 
 	return ctx.JSON(response.NewResponse(res))
 }
+
+func (h *reactionHandler) GetReactors(ctx fiber.Ctx) error {
+	targetType := ctx.Params("target_type")
+	targetID := fiber.Params[uint](ctx, "id")
+	requestID := requestid.FromContext(ctx)
+
+	var params ReactorsParams
+	if err := ctx.Bind().Query(&params); err != nil {
+		return errors.ErrInvalidQuery
+	}
+
+	data, err := h.reactionService.GetReactors(
+		context.WithValue(ctx, logger.RequestIDKey, requestID),
+		targetType,
+		targetID,
+		params,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(response.NewCursorPaginatedResponse(nil, data.NextCursor, "", data.Result))
+}
+
+func (h *reactionHandler) ListReactionTypes(ctx fiber.Ctx) error {
+	requestID := requestid.FromContext(ctx)
+
+	res, err := h.reactionService.ListReactionTypes(context.WithValue(ctx, logger.RequestIDKey, requestID))
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(response.NewResponse(res))
+}
+
+func (h *reactionHandler) CreateReactionType(ctx fiber.Ctx) error {
+	requestID := requestid.FromContext(ctx)
+
+	var input CreateReactionTypeInput
+	if err := ctx.Bind().JSON(&input); err != nil {
+		return err
+	}
+
+	res, err := h.reactionService.CreateReactionType(
+		context.WithValue(ctx, logger.RequestIDKey, requestID),
+		input,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(response.NewResponse(res))
+}
+
+func (h *reactionHandler) UpdateReactionType(ctx fiber.Ctx) error {
+	reactionTypeID := fiber.Params[uint](ctx, "id")
+
+	requestID := requestid.FromContext(ctx)
+
+	var input UpdateReactionTypeInput
+	if err := ctx.Bind().JSON(&input); err != nil {
+		return err
+	}
+
+	res, err := h.reactionService.UpdateReactionType(
+		context.WithValue(ctx, logger.RequestIDKey, requestID),
+		reactionTypeID,
+		input,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(response.NewResponse(res))
+}
+
+func (h *reactionHandler) ReorderReactionTypes(ctx fiber.Ctx) error {
+	requestID := requestid.FromContext(ctx)
+
+	var input ReorderReactionTypesInput
+	if err := ctx.Bind().JSON(&input); err != nil {
+		return err
+	}
+
+	res, err := h.reactionService.ReorderReactionTypes(
+		context.WithValue(ctx, logger.RequestIDKey, requestID),
+		input,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(response.NewResponse(res))
+}
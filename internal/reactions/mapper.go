@@ -1,6 +1,9 @@
 package reactions
 
-import "blog-api/internal/models"
+import (
+	"blog-api/internal/models"
+	"blog-api/internal/users"
+)
 
 func MapReactionTypesToResponse(reactionTypes []models.ReactionType) []*ReactionTypeResponse {
 	output := make([]*ReactionTypeResponse, len(reactionTypes))
@@ -20,5 +23,23 @@ func MapReactionTypeToResponse(reactionType models.ReactionType) *ReactionTypeRe
 		Type:       reactionType.Name,
 		Icon:       reactionType.Icon,
 		IsActive:   reactionType.IsActive,
+		SortOrder:  reactionType.SortOrder,
 	}
 }
+
+func MapReactionsToReactors(reactions []models.Reaction) []*ReactorResponse {
+	output := make([]*ReactorResponse, len(reactions))
+	for i, reaction := range reactions {
+		output[i] = &ReactorResponse{
+			User: users.MapUserToResponse(reaction.User),
+			Reaction: &models.UserReaction{
+				TargetID: reaction.TargetID,
+				Type:     reaction.ReactionType.Name,
+				Icon:     reaction.ReactionType.Icon,
+				IsActive: reaction.ReactionType.IsActive,
+			},
+			ReactedAt: reaction.CreatedAt,
+		}
+	}
+	return output
+}
@@ -0,0 +1,98 @@
+package reactions
+
+import (
+	"blog-api/internal/database"
+	"blog-api/internal/errors"
+	"blog-api/internal/logger"
+	"blog-api/internal/models"
+	"blog-api/internal/users"
+	"blog-api/pkg/cursor"
+	"context"
+	goerrors "errors"
+	"log/slog"
+)
+
+const (
+	defaultListLimit = 30
+	maxListLimit     = 100
+)
+
+// GetReactors returns the users who reacted to a target, newest reaction
+// first, once per reaction they left. Reactions of deleted users are left
+// out. Users have no privacy settings or blocks to respect yet; they belong
+// in this query once they do.
+func (s *reactionService) GetReactors(ctx context.Context, targetType string, targetID uint, params ReactorsParams) (*ReactorsResponse, error) {
+	db := s.db.WithContext(ctx)
+	log := logger.FromCtx(ctx, s.logger).With(
+		slog.String("target_type", targetType),
+		slog.Uint64("target_id", uint64(targetID)),
+	)
+
+	if _, err := s.lookupTarget(db, log, targetType, targetID); err != nil {
+		return nil, err
+	}
+
+	log.Info("fetching reactors")
+
+	limit := params.Limit
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	limit = min(limit, maxListLimit)
+
+	query := db.Model(&models.Reaction{}).
+		Joins("JOIN users ON users.id = reactions.user_id AND users.deleted_at IS NULL").
+		Where("reactions.target_type = ? AND reactions.target_id = ?", targetType, targetID).
+		Order("reactions.created_at DESC, reactions.id DESC").
+		Limit(limit + 1)
+
+	if params.Type != "" {
+		var reactionType models.ReactionType
+		if err := db.Where("name = ?", params.Type).First(&reactionType).Error; err != nil {
+			if goerrors.Is(err, database.ErrRecordNotFound) {
+				log.Warn("unknown reaction type", slog.String("type", params.Type))
+				return nil, errors.BadRequest("unknown reaction type")
+			}
+			log.Error("failed to fetch reaction type", logger.Err(err))
+			return nil, err
+		}
+		query = query.Where("reactions.reaction_type_id = ?", reactionType.ID)
+	}
+
+	if params.Cursor != "" {
+		c, err := cursor.Decode[cursor.CreatedAtID](params.Cursor)
+		if err != nil {
+			log.Warn("failed to decode cursor", logger.Err(err))
+			return nil, errors.ErrInvalidCursor
+		}
+		query = query.Where("(reactions.created_at, reactions.id) < (?, ?)", c.CreatedAt, c.ID)
+	}
+
+	var reactions []models.Reaction
+	if err := query.Preload("User").Preload("ReactionType").Find(&reactions).Error; err != nil {
+		log.Error("failed to fetch reactions from database", logger.Err(err))
+		return nil, err
+	}
+
+	res := &ReactorsResponse{}
+	if len(reactions) > limit {
+		reactions = reactions[:limit]
+
+		last := reactions[len(reactions)-1]
+		nextCursor, err := cursor.Encode(cursor.CreatedAtID{CreatedAt: last.CreatedAt, ID: last.ID})
+		if err != nil {
+			log.Error("failed to encode next cursor", logger.Err(err))
+			return nil, err
+		}
+		res.NextCursor = nextCursor
+	}
+
+	res.Result = MapReactionsToReactors(reactions)
+	for _, r := range res.Result {
+		users.SetAvatarURLs(s.urls.URL, r.User)
+	}
+
+	log.Info("reactors retrieved successfully", slog.Int("returned", len(reactions)))
+
+	return res, nil
+}
@@ -6,14 +6,16 @@ import (
 	"gorm.io/gorm"
 )
 
+// GetReactionsAggregate reads the reaction counters of the targets, in the
+// order reaction types are listed in.
 func GetReactionsAggregate(db *gorm.DB, targetType string, targetIDs []uint) (map[uint][]models.ReactionStat, error) {
 	var results []models.ReactionStat
 
-	err := db.Table("reactions r").
-		Select("r.target_id as target_id, rt.name as type, rt.icon as icon, rt.is_active as is_active, COUNT(*) as count").
-		Joins("JOIN reaction_types rt on r.reaction_type_id = rt.id").
-		Where("r.target_type = ? AND r.target_id IN ?", targetType, targetIDs).
-		Group("r.target_id, rt.name, rt.icon, rt.is_active").
+	err := db.Table("reaction_counts rc").
+		Select("rc.target_id as target_id, rt.name as type, rt.icon as icon, rt.is_active as is_active, rc.count as count").
+		Joins("JOIN reaction_types rt on rc.reaction_type_id = rt.id").
+		Where("rc.target_type = ? AND rc.target_id IN ? AND rc.count > 0", targetType, targetIDs).
+		Order("rt.sort_order, rt.id").
 		Scan(&results).Error
 	if err != nil {
 		return nil, err
@@ -32,22 +34,78 @@ func GetReactionsAggregate(db *gorm.DB, targetType string, targetIDs []uint) (ma
 	return aggMap, nil
 }
 
-func GetUserReactions(db *gorm.DB, targetType string, targetIDs []uint, userID uint) (map[uint]*models.UserReaction, error) {
+// GetUserReactions returns the reactions the user left on the targets, in
+// the order reaction types are listed in.
+func GetUserReactions(db *gorm.DB, targetType string, targetIDs []uint, userID uint) (map[uint][]models.UserReaction, error) {
 	var results []models.UserReaction
 
 	err := db.Table("reactions r").
 		Select(`r.target_id as target_id, rt.name as type, rt.icon as icon, rt.is_active as is_active`).
 		Joins("JOIN reaction_types rt ON r.reaction_type_id = rt.id").
 		Where("r.target_type = ? AND r.target_id IN ? AND r.user_id = ?", targetType, targetIDs, userID).
+		Order("rt.sort_order, rt.id").
 		Scan(&results).Error
 	if err != nil {
 		return nil, err
 	}
 
-	userMap := make(map[uint]*models.UserReaction)
+	userMap := make(map[uint][]models.UserReaction)
 	for _, r := range results {
-		userMap[r.TargetID] = &r
+		userMap[r.TargetID] = append(userMap[r.TargetID], r)
 	}
 
 	return userMap, nil
 }
+
+// changeReactionCount adds delta to the counter of a reaction type on a
+// target. It must run in the transaction changing the reactions.
+func changeReactionCount(tx *gorm.DB, targetType string, targetID, reactionTypeID uint, delta int64) error {
+	return tx.Exec(`
+		INSERT INTO reaction_counts (target_type, target_id, reaction_type_id, count)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (target_type, target_id, reaction_type_id)
+		DO UPDATE SET count = reaction_counts.count + EXCLUDED.count`,
+		targetType, targetID, reactionTypeID, delta,
+	).Error
+}
+
+// ReconcileReactionCounts rebuilds the reaction counters from the reactions
+// and returns how many counters it changed. Reactions cannot change while it
+// runs.
+func ReconcileReactionCounts(db *gorm.DB) (int64, error) {
+	var fixed int64
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("LOCK TABLE reactions IN SHARE MODE").Error; err != nil {
+			return err
+		}
+
+		res := tx.Exec(`
+			INSERT INTO reaction_counts (target_type, target_id, reaction_type_id, count)
+			SELECT target_type, target_id, reaction_type_id, COUNT(*)
+			FROM reactions
+			GROUP BY target_type, target_id, reaction_type_id
+			ON CONFLICT (target_type, target_id, reaction_type_id)
+			DO UPDATE SET count = EXCLUDED.count
+			WHERE reaction_counts.count <> EXCLUDED.count`)
+		if res.Error != nil {
+			return res.Error
+		}
+		fixed += res.RowsAffected
+
+		// counters of reactions that are all gone
+		res = tx.Exec(`
+			DELETE FROM reaction_counts rc
+			WHERE NOT EXISTS (
+				SELECT 1 FROM reactions r
+				WHERE r.target_type = rc.target_type
+					AND r.target_id = rc.target_id
+					AND r.reaction_type_id = rc.reaction_type_id
+			)`)
+		if res.Error != nil {
+			return res.Error
+		}
+		fixed += res.RowsAffected
+		return nil
+	})
+	return fixed, err
+}
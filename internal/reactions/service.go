@@ -5,139 +5,191 @@ import (
 	"blog-api/internal/errors"
 	"blog-api/internal/logger"
 	"blog-api/internal/models"
+	"blog-api/internal/storage"
 	"context"
 	goerrors "errors"
 	"log/slog"
 	"slices"
 
 	"gorm.io/gorm"
-)
-
-const (
-	TargetPost = "posts"
-)
-
-var (
-	allowedTargets = []string{TargetPost}
+	"gorm.io/gorm/clause"
 )
 
 type IReactionService interface {
+	SetReaction(ctx context.Context, userID uint, input SetReactionInput) (*ReactionResponse, error)
+	GetReactions(ctx context.Context, targetType string, targetID uint, userID *uint) (*ReactionResponse, error)
+	GetReactors(ctx context.Context, targetType string, targetID uint, params ReactorsParams) (*ReactorsResponse, error)
 	SetPostReaction(ctx context.Context, userID uint, input SetPostReactionInput) (*ReactionResponse, error)
+	SetCommentReaction(ctx context.Context, userID uint, input SetCommentReactionInput) (*ReactionResponse, error)
 	GetAvailableReactions(ctx context.Context) ([]*ReactionTypeResponse, error)
+
+	ListReactionTypes(ctx context.Context) ([]*ReactionTypeResponse, error)
+	CreateReactionType(ctx context.Context, input CreateReactionTypeInput) (*ReactionTypeResponse, error)
+	UpdateReactionType(ctx context.Context, reactionTypeID uint, input UpdateReactionTypeInput) (*ReactionTypeResponse, error)
+	ReorderReactionTypes(ctx context.Context, input ReorderReactionTypesInput) ([]*ReactionTypeResponse, error)
 }
 
 type reactionService struct {
-	db     *database.DB
-	logger *slog.Logger
+	db      *database.DB
+	urls    storage.URLResolver
+	targets *TargetRegistry
+	logger  *slog.Logger
 }
 
-func NewReactionService(db *database.DB, logger *slog.Logger) IReactionService {
+func NewReactionService(db *database.DB, urls storage.URLResolver, targets *TargetRegistry, logger *slog.Logger) IReactionService {
 	return &reactionService{
-		db:     db,
-		logger: logger,
+		db:      db,
+		urls:    urls,
+		targets: targets,
+		logger:  logger,
 	}
 }
 
-func (s *reactionService) setReaction(ctx context.Context, userID uint, input SetReactionInput) (*ReactionResponse, error) {
+// SetReaction toggles a reaction of the user on a target: the same reaction
+// again removes it. A different one is added next to the reactions the user
+// already left, up to the limit of the target type; when that limit is 1 it
+// replaces the previous reaction instead.
+func (s *reactionService) SetReaction(ctx context.Context, userID uint, input SetReactionInput) (*ReactionResponse, error) {
 	log := logger.WithUserID(logger.FromCtx(ctx, s.logger), userID).With(
 		slog.String("target_type", input.TargetType),
 		slog.Uint64("target_id", uint64(input.TargetID)),
 		slog.Uint64("reaction_type_id", uint64(input.ReactionID)),
 	)
 
-	if !slices.Contains(allowedTargets, input.TargetType) {
-		log.Warn("invalid target type")
-		return nil, errors.BadRequest("invalid target type")
-	}
-
 	db := s.db.WithContext(ctx)
 
+	target, err := s.lookupTarget(db, log, input.TargetType, input.TargetID)
+	if err != nil {
+		return nil, err
+	}
+	if err := target.Authorize(db, userID, input.TargetID); err != nil {
+		log.Warn("reaction not allowed", logger.Err(err))
+		return nil, err
+	}
+
 	var reactType models.ReactionType
-	if err := db.Where("id = ? AND is_active = ?", input.ReactionID, "true").First(&reactType).Error; err != nil {
+	// inactive types are looked up too, so that reactions made before the
+	// type was deactivated can still be removed
+	if err := db.Where("id = ?", input.ReactionID).First(&reactType).Error; err != nil {
 		if goerrors.Is(err, database.ErrRecordNotFound) {
-			log.Warn("invalid or inactive reaction")
+			log.Warn("invalid reaction")
 			return nil, errors.BadRequest("invalid or inactive reaction")
 		}
 		return nil, err
 	}
 
-	var finalReaction *models.Reaction
-	err := db.Transaction(func(tx *gorm.DB) error {
-		var existing models.Reaction
-		err := tx.Where("user_id = ? AND target_type = ? AND target_id = ?",
+	err = db.Transaction(func(tx *gorm.DB) error {
+		// reactions of the same user are serialized, so that concurrent
+		// requests can neither count a change twice nor exceed the limit
+		if err := tx.Clauses(clause.Locking{Strength: "NO KEY UPDATE"}).
+			Select("id").
+			First(&models.User{}, userID).Error; err != nil {
+			log.Error("failed to lock user", logger.Err(err))
+			return err
+		}
+
+		var existing []models.Reaction
+		if err := tx.Where("user_id = ? AND target_type = ? AND target_id = ?",
 			userID, input.TargetType, input.TargetID,
-		).First(&existing).Error
+		).Order("created_at, id").Find(&existing).Error; err != nil {
+			log.Error("failed to fetch reactions", logger.Err(err))
+			return err
+		}
+
+		same := slices.IndexFunc(existing, func(r models.Reaction) bool {
+			return r.ReactionTypeID == input.ReactionID
+		})
 
 		switch {
-		case err == nil && existing.ReactionTypeID == input.ReactionID:
+		case same >= 0:
 			log.Info("removing existing reaction")
-			if e := tx.Delete(&existing).Error; e != nil {
-				log.Error("failed to delete reaction", logger.Err(e))
-				return err
-			}
-			finalReaction = nil
-
-		case err == nil:
-			log.Info("updating reaction", slog.Uint64("new_reaction_type_id", uint64(input.ReactionID)))
-			existing.ReactionTypeID = input.ReactionID
-			if e := tx.Save(&existing).Error; e != nil {
-				log.Error("failed to update reaction", logger.Err(e))
-				return err
-			}
-			finalReaction = &existing
+			return s.removeReaction(tx, log, existing[same])
 
-		case goerrors.Is(err, gorm.ErrRecordNotFound):
+		case !reactType.IsActive:
+			log.Warn("inactive reaction")
+			return errors.BadRequest("invalid or inactive reaction")
+
+		case len(existing) < target.maxPerUser:
 			log.Info("creating new reaction")
-			newReaction := models.Reaction{
+			return s.addReaction(tx, log, models.Reaction{
 				UserID:         userID,
 				TargetID:       input.TargetID,
 				TargetType:     input.TargetType,
 				ReactionTypeID: input.ReactionID,
+			})
+
+		case target.maxPerUser == 1:
+			// more than one is left over from a higher limit
+			log.Info("replacing reaction", slog.Int("previous", len(existing)))
+			for _, r := range existing {
+				if err := s.removeReaction(tx, log, r); err != nil {
+					return err
+				}
 			}
-			if e := tx.Create(&newReaction).Error; e != nil {
-				log.Error("failed to create reaction", logger.Err(e))
-				return e
-			}
-			finalReaction = &newReaction
+			return s.addReaction(tx, log, models.Reaction{
+				UserID:         userID,
+				TargetID:       input.TargetID,
+				TargetType:     input.TargetType,
+				ReactionTypeID: input.ReactionID,
+			})
 
 		default:
-			log.Error("failed to fetch reaction", logger.Err(err))
-			return err
+			log.Warn("reaction limit reached", slog.Int("limit", target.maxPerUser))
+			return errors.ErrReactionLimitReached.WithDetails(map[string]int{"limit": target.maxPerUser})
 		}
-		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
 
 	aggReact, err := GetReactionsAggregate(db, input.TargetType, []uint{input.TargetID})
 	if err != nil {
 		log.Error("failed to aggregate reactions", logger.Err(err))
 		return nil, err
 	}
-	reactions := aggReact[input.TargetID]
 
-	reactResponse := &ReactionResponse{
-		UserID:     userID,
-		TargetID:   input.TargetID,
-		TargetType: input.TargetType,
-		Reactions:  reactions,
+	userReactions, err := GetUserReactions(db, input.TargetType, []uint{input.TargetID}, userID)
+	if err != nil {
+		log.Error("failed to get user reactions", logger.Err(err))
+		return nil, err
 	}
 
-	if finalReaction == nil { // reaction removed
-		log.Info("reaction removed successfully")
-		return reactResponse, nil
+	log.Info("reaction toggled successfully")
+	return &ReactionResponse{
+		UserID:        userID,
+		TargetID:      input.TargetID,
+		TargetType:    input.TargetType,
+		UserReactions: userReactions[input.TargetID],
+		Reactions:     aggReact[input.TargetID],
+	}, nil
+}
+
+func (s *reactionService) addReaction(tx *gorm.DB, log *slog.Logger, reaction models.Reaction) error {
+	if err := tx.Create(&reaction).Error; err != nil {
+		log.Error("failed to create reaction", logger.Err(err))
+		return err
 	}
+	if err := changeReactionCount(tx, reaction.TargetType, reaction.TargetID, reaction.ReactionTypeID, 1); err != nil {
+		log.Error("failed to update reaction count", logger.Err(err))
+		return err
+	}
+	return nil
+}
 
-	reactResponse.UserReaction = &models.UserReaction{
-		TargetID: input.TargetID,
-		Type:     reactType.Name,
-		Icon:     reactType.Icon,
-		IsActive: reactType.IsActive,
+func (s *reactionService) removeReaction(tx *gorm.DB, log *slog.Logger, reaction models.Reaction) error {
+	if err := tx.Delete(&reaction).Error; err != nil {
+		log.Error("failed to delete reaction", logger.Err(err))
+		return err
+	}
+	if err := changeReactionCount(tx, reaction.TargetType, reaction.TargetID, reaction.ReactionTypeID, -1); err != nil {
+		log.Error("failed to update reaction count", logger.Err(err))
+		return err
 	}
-	log.Info("reaction set successfully")
-	return reactResponse, nil
+	return nil
 }
 
 func (s *reactionService) SetPostReaction(ctx context.Context, userID uint, input SetPostReactionInput) (*ReactionResponse, error) {
-	return s.setReaction(ctx, userID,
+	return s.SetReaction(ctx, userID,
 		SetReactionInput{
 			TargetType: TargetPost,
 			TargetID:   input.PostID,
@@ -146,12 +198,80 @@ func (s *reactionService) SetPostReaction(ctx context.Context, userID uint, inpu
 	)
 }
 
+func (s *reactionService) SetCommentReaction(ctx context.Context, userID uint, input SetCommentReactionInput) (*ReactionResponse, error) {
+	return s.SetReaction(ctx, userID,
+		SetReactionInput{
+			TargetType: TargetComment,
+			TargetID:   input.CommentID,
+			ReactionID: input.ReactionID,
+		},
+	)
+}
+
+// GetReactions returns the reaction counts of a target, and the reaction of
+// the user if there is one.
+func (s *reactionService) GetReactions(ctx context.Context, targetType string, targetID uint, userID *uint) (*ReactionResponse, error) {
+	db := s.db.WithContext(ctx)
+	log := logger.FromCtx(ctx, s.logger).With(
+		slog.String("target_type", targetType),
+		slog.Uint64("target_id", uint64(targetID)),
+	)
+
+	if _, err := s.lookupTarget(db, log, targetType, targetID); err != nil {
+		return nil, err
+	}
+
+	aggReact, err := GetReactionsAggregate(db, targetType, []uint{targetID})
+	if err != nil {
+		log.Error("failed to aggregate reactions", logger.Err(err))
+		return nil, err
+	}
+
+	res := &ReactionResponse{
+		TargetID:   targetID,
+		TargetType: targetType,
+		Reactions:  aggReact[targetID],
+	}
+	if userID == nil {
+		return res, nil
+	}
+
+	userReactions, err := GetUserReactions(db, targetType, []uint{targetID}, *userID)
+	if err != nil {
+		log.Error("failed to get user reaction", logger.Err(err))
+		return nil, err
+	}
+	res.UserID = *userID
+	res.UserReactions = userReactions[targetID]
+	return res, nil
+}
+
+// lookupTarget resolves a target type and checks that the target exists.
+func (s *reactionService) lookupTarget(db *gorm.DB, log *slog.Logger, targetType string, targetID uint) (registeredTarget, error) {
+	target, ok := s.targets.targets[targetType]
+	if !ok {
+		log.Warn("invalid target type")
+		return target, errors.BadRequest("invalid target type")
+	}
+
+	found, err := target.Exists(db, targetID)
+	if err != nil {
+		log.Error("failed to check reaction target", logger.Err(err))
+		return target, err
+	}
+	if !found {
+		log.Warn("reaction target not found")
+		return target, errors.ErrNotFound
+	}
+	return target, nil
+}
+
 func (s *reactionService) GetAvailableReactions(ctx context.Context) ([]*ReactionTypeResponse, error) {
 	db := s.db.WithContext(ctx)
 	log := logger.FromCtx(ctx, s.logger)
 
 	var availableReactionTypes []models.ReactionType
-	if err := db.Find(&availableReactionTypes, "is_active = true").Error; err != nil {
+	if err := db.Order("sort_order, id").Find(&availableReactionTypes, "is_active = true").Error; err != nil {
 		log.Error("failed to get available reaction types", logger.Err(err))
 		return nil, err
 	}
@@ -0,0 +1,122 @@
+package reactions
+
+import (
+	"blog-api/config"
+	"blog-api/internal/errors"
+	"blog-api/internal/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	TargetPost    = "posts"
+	TargetComment = "comments"
+	TargetUser    = "users"
+	TargetMedia   = "media"
+)
+
+// Target is a kind of thing users can react to.
+type Target interface {
+	// Exists reports whether the target with the given ID can be seen, and
+	// thus reacted to.
+	Exists(db *gorm.DB, id uint) (bool, error)
+	// Authorize checks that the user may react to an existing target.
+	Authorize(db *gorm.DB, userID, id uint) error
+}
+
+// TargetRegistry maps target types, as used in URLs and stored with the
+// reactions, to their targets.
+type TargetRegistry struct {
+	targets map[string]registeredTarget
+}
+
+type registeredTarget struct {
+	Target
+	// maxPerUser is how many distinct reaction types a user may leave on
+	// one target
+	maxPerUser int
+}
+
+func NewTargetRegistry() *TargetRegistry {
+	return &TargetRegistry{targets: make(map[string]registeredTarget)}
+}
+
+// DefaultTargets returns a registry with every target type of the API.
+func DefaultTargets(cfg config.ReactionsConfig) *TargetRegistry {
+	r := NewTargetRegistry()
+	r.Register(TargetPost, postTarget{}, cfg.MaxPerUser[TargetPost])
+	r.Register(TargetComment, commentTarget{}, cfg.MaxPerUser[TargetComment])
+	r.Register(TargetUser, userTarget{}, cfg.MaxPerUser[TargetUser])
+	r.Register(TargetMedia, mediaTarget{}, cfg.MaxPerUser[TargetMedia])
+	return r
+}
+
+// Register adds a target type. Users may leave up to maxPerUser distinct
+// reaction types on each target; anything below 1 counts as 1.
+func (r *TargetRegistry) Register(targetType string, target Target, maxPerUser int) {
+	r.targets[targetType] = registeredTarget{Target: target, maxPerUser: max(maxPerUser, 1)}
+}
+
+func (r *TargetRegistry) Lookup(targetType string) (Target, bool) {
+	target, ok := r.targets[targetType]
+	return target.Target, ok
+}
+
+func exists(db *gorm.DB) (bool, error) {
+	var count int64
+	if err := db.Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+type postTarget struct{}
+
+func (postTarget) Exists(db *gorm.DB, id uint) (bool, error) {
+	return exists(db.Model(&models.Post{}).Where("id = ?", id))
+}
+
+func (postTarget) Authorize(*gorm.DB, uint, uint) error {
+	return nil
+}
+
+// commentTarget accepts comments whose post is not deleted either.
+type commentTarget struct{}
+
+func (commentTarget) Exists(db *gorm.DB, id uint) (bool, error) {
+	return exists(db.Model(&models.Comment{}).
+		Joins("JOIN posts ON posts.id = comments.post_id AND posts.deleted_at IS NULL").
+		Where("comments.id = ?", id))
+}
+
+func (commentTarget) Authorize(*gorm.DB, uint, uint) error {
+	return nil
+}
+
+// userTarget is the profile of a user. Users cannot react to their own.
+type userTarget struct{}
+
+func (userTarget) Exists(db *gorm.DB, id uint) (bool, error) {
+	return exists(db.Model(&models.User{}).Where("id = ?", id))
+}
+
+func (userTarget) Authorize(_ *gorm.DB, userID, id uint) error {
+	if userID == id {
+		return errors.ErrCannotReactToSelf
+	}
+	return nil
+}
+
+// mediaTarget accepts media attached to a post. Media that is not attached
+// yet is only visible to its uploader, so it is treated as nonexistent.
+type mediaTarget struct{}
+
+func (mediaTarget) Exists(db *gorm.DB, id uint) (bool, error) {
+	return exists(db.Model(&models.PostAttachment{}).
+		Joins("JOIN posts ON posts.id = post_attachments.post_id AND posts.deleted_at IS NULL").
+		Where("post_attachments.media_id = ?", id))
+}
+
+func (mediaTarget) Authorize(*gorm.DB, uint, uint) error {
+	return nil
+}
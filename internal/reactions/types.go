@@ -0,0 +1,175 @@
+package reactions
+
+import (
+	"blog-api/internal/database"
+	"blog-api/internal/errors"
+	"blog-api/internal/logger"
+	"blog-api/internal/models"
+	"context"
+	goerrors "errors"
+	"log/slog"
+
+	"gorm.io/gorm"
+)
+
+// Reaction types are managed by admins. They are never deleted: deactivating
+// a type only stops it from being picked, and reactions made with it keep
+// being counted.
+
+func (s *reactionService) ListReactionTypes(ctx context.Context) ([]*ReactionTypeResponse, error) {
+	log := logger.FromCtx(ctx, s.logger)
+
+	var reactionTypes []models.ReactionType
+	if err := s.db.WithContext(ctx).Order("sort_order, id").Find(&reactionTypes).Error; err != nil {
+		log.Error("failed to get reaction types", logger.Err(err))
+		return nil, err
+	}
+
+	return MapReactionTypesToResponse(reactionTypes), nil
+}
+
+func (s *reactionService) CreateReactionType(ctx context.Context, input CreateReactionTypeInput) (*ReactionTypeResponse, error) {
+	db := s.db.WithContext(ctx)
+	log := logger.FromCtx(ctx, s.logger).With(slog.String("name", input.Name))
+
+	log.Info("creating reaction type")
+
+	if err := s.ensureTypeNameAvailable(db, log, input.Name); err != nil {
+		return nil, err
+	}
+
+	reactionType := models.ReactionType{
+		Name:     input.Name,
+		Icon:     input.Icon,
+		IsActive: true,
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// new types go last
+		if err := tx.Model(&models.ReactionType{}).
+			Select("COALESCE(MAX(sort_order) + 1, 0)").
+			Scan(&reactionType.SortOrder).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Create(&reactionType).Error; err != nil {
+			return err
+		}
+
+		// GORM writes the column default in place of a false is_active on
+		// create, so an inactive type is deactivated right after
+		if input.IsActive != nil && !*input.IsActive {
+			reactionType.IsActive = false
+			return tx.Model(&reactionType).Update("is_active", false).Error
+		}
+		return nil
+	})
+	if err != nil {
+		log.Error("failed to create reaction type", logger.Err(err))
+		return nil, err
+	}
+
+	log.Info("reaction type created successfully", slog.Uint64("reaction_type_id", uint64(reactionType.ID)))
+
+	return MapReactionTypeToResponse(reactionType), nil
+}
+
+func (s *reactionService) UpdateReactionType(ctx context.Context, reactionTypeID uint, input UpdateReactionTypeInput) (*ReactionTypeResponse, error) {
+	db := s.db.WithContext(ctx)
+	log := logger.FromCtx(ctx, s.logger).With(slog.Uint64("reaction_type_id", uint64(reactionTypeID)))
+
+	log.Info("updating reaction type")
+
+	var reactionType models.ReactionType
+	if err := db.First(&reactionType, reactionTypeID).Error; err != nil {
+		if goerrors.Is(err, database.ErrRecordNotFound) {
+			log.Warn("reaction type not found")
+			return nil, errors.ErrNotFound
+		}
+		log.Error("failed to fetch reaction type", logger.Err(err))
+		return nil, err
+	}
+
+	updates := make(map[string]any)
+	if input.Name != nil && *input.Name != reactionType.Name {
+		if err := s.ensureTypeNameAvailable(db, log, *input.Name); err != nil {
+			return nil, err
+		}
+		updates["name"] = *input.Name
+		reactionType.Name = *input.Name
+	}
+	if input.Icon != nil {
+		updates["icon"] = *input.Icon
+		reactionType.Icon = *input.Icon
+	}
+	if input.IsActive != nil {
+		updates["is_active"] = *input.IsActive
+		reactionType.IsActive = *input.IsActive
+	}
+
+	if len(updates) > 0 {
+		if err := db.Model(&reactionType).Updates(updates).Error; err != nil {
+			log.Error("failed to update reaction type", logger.Err(err))
+			return nil, err
+		}
+	}
+
+	log.Info("reaction type updated successfully")
+
+	return MapReactionTypeToResponse(reactionType), nil
+}
+
+// ReorderReactionTypes sets the order reaction types are listed in. The input
+// must list every reaction type, active or not, exactly once.
+func (s *reactionService) ReorderReactionTypes(ctx context.Context, input ReorderReactionTypesInput) ([]*ReactionTypeResponse, error) {
+	log := logger.FromCtx(ctx, s.logger)
+
+	log.Info("reordering reaction types")
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.ReactionType{}).
+			Where("id IN ?", input.ReactionIDs).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		var total int64
+		if err := tx.Model(&models.ReactionType{}).Count(&total).Error; err != nil {
+			return err
+		}
+		if count != int64(len(input.ReactionIDs)) || count != total {
+			return errors.BadRequest("reaction_ids must list every reaction type exactly once")
+		}
+
+		for i, id := range input.ReactionIDs {
+			if err := tx.Model(&models.ReactionType{}).
+				Where("id = ?", id).
+				Update("sort_order", i).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Warn("failed to reorder reaction types", logger.Err(err))
+		return nil, err
+	}
+
+	log.Info("reaction types reordered successfully")
+
+	return s.ListReactionTypes(ctx)
+}
+
+func (s *reactionService) ensureTypeNameAvailable(db *gorm.DB, log *slog.Logger, name string) error {
+	var count int64
+	if err := db.Model(&models.ReactionType{}).Where("name = ?", name).Count(&count).Error; err != nil {
+		log.Error("failed to check reaction type name", logger.Err(err))
+		return err
+	}
+
+	if count > 0 {
+		log.Info("reaction type name already taken")
+		return errors.ErrReactionTypeAlreadyExists
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package renderer
+
+import (
+	"blog-api/internal/models"
+	"html"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+type htmlMarkup struct{}
+
+func (htmlMarkup) open(e models.Entity, _ string) string {
+	switch e.Type {
+	case models.EntityBold:
+		return "<b>"
+	case models.EntityItalic:
+		return "<i>"
+	case models.EntityUnderline:
+		return "<u>"
+	case models.EntityStrikethrough:
+		return "<s>"
+	case models.EntitySpoiler:
+		return `<span class="spoiler">`
+	case models.EntityLink:
+		return `<a href="` + html.EscapeString(safeURL(e.URL)) + `" rel="nofollow noopener noreferrer">`
+	case models.EntityCode:
+		return "<code>"
+	case models.EntityPre:
+		if e.Language != nil {
+			return `<pre><code class="language-` + html.EscapeString(*e.Language) + `">`
+		}
+		return "<pre><code>"
+	case models.EntityBlockquote:
+		return "<blockquote>"
+	case models.EntityMention:
+		if e.UserID != nil {
+			return `<span class="mention" data-user-id="` + strconv.FormatUint(uint64(*e.UserID), 10) + `">`
+		}
+		return `<span class="mention">`
+	case models.EntityHashtag:
+		return `<span class="hashtag">`
+	}
+	return ""
+}
+
+func (htmlMarkup) close(e models.Entity, _ string) string {
+	switch e.Type {
+	case models.EntityBold:
+		return "</b>"
+	case models.EntityItalic:
+		return "</i>"
+	case models.EntityUnderline:
+		return "</u>"
+	case models.EntityStrikethrough:
+		return "</s>"
+	case models.EntitySpoiler, models.EntityMention, models.EntityHashtag:
+		return "</span>"
+	case models.EntityLink:
+		return "</a>"
+	case models.EntityCode:
+		return "</code>"
+	case models.EntityPre:
+		return "</code></pre>"
+	case models.EntityBlockquote:
+		return "</blockquote>"
+	}
+	return ""
+}
+
+func (htmlMarkup) hugs(models.Entity) bool {
+	return false
+}
+
+func (htmlMarkup) escape(s string, active []models.Entity) string {
+	if inside(active, models.EntityPre) {
+		return html.EscapeString(s)
+	}
+	return strings.ReplaceAll(html.EscapeString(s), "\n", "<br>")
+}
+
+// inside reports whether any of the active entities has one of the types.
+func inside(active []models.Entity, types ...string) bool {
+	for _, e := range active {
+		for _, t := range types {
+			if e.Type == t {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// safeURL only lets through absolute http(s) URLs, anything else (javascript:,
+// data:, relative paths) is replaced with a harmless anchor.
+func safeURL(raw *string) string {
+	if raw == nil {
+		return "#"
+	}
+
+	u, err := url.Parse(*raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "#"
+	}
+	return u.String()
+}
@@ -0,0 +1,137 @@
+package renderer
+
+import (
+	"blog-api/internal/models"
+	"strings"
+)
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"`", "\\`",
+	`*`, `\*`,
+	`_`, `\_`,
+	`[`, `\[`,
+	`]`, `\]`,
+	`(`, `\(`,
+	`)`, `\)`,
+	`<`, `\<`,
+	`>`, `\>`,
+	`#`, `\#`,
+	`@`, `\@`,
+	`|`, `\|`,
+	`~`, `\~`,
+	`&`, `\&`,
+)
+
+var markdownURLEscaper = strings.NewReplacer(
+	"(", "%28",
+	")", "%29",
+	"<", "%3C",
+	">", "%3E",
+	" ", "%20",
+)
+
+type markdownMarkup struct{}
+
+func (markdownMarkup) open(e models.Entity, body string) string {
+	switch e.Type {
+	case models.EntityBold:
+		return "**"
+	case models.EntityItalic:
+		// not _, which does not toggle formatting inside a word
+		return "*"
+	case models.EntityUnderline:
+		return "<u>"
+	case models.EntityStrikethrough:
+		return "~~"
+	case models.EntitySpoiler:
+		return "||"
+	case models.EntityLink:
+		return "["
+	case models.EntityCode:
+		return codeFence(body) + codePadding(body)
+	case models.EntityPre:
+		fence := strings.Repeat("`", max(3, longestRun(body, '`')+1))
+		if e.Language != nil {
+			return fence + *e.Language + "\n"
+		}
+		return fence + "\n"
+	case models.EntityBlockquote:
+		return "> "
+	}
+	return ""
+}
+
+func (markdownMarkup) close(e models.Entity, body string) string {
+	switch e.Type {
+	case models.EntityBold:
+		return "**"
+	case models.EntityItalic:
+		return "*"
+	case models.EntityUnderline:
+		return "</u>"
+	case models.EntityStrikethrough:
+		return "~~"
+	case models.EntitySpoiler:
+		return "||"
+	case models.EntityLink:
+		return "](" + markdownURLEscaper.Replace(safeURL(e.URL)) + ")"
+	case models.EntityCode:
+		return codePadding(body) + codeFence(body)
+	case models.EntityPre:
+		return "\n" + strings.Repeat("`", max(3, longestRun(body, '`')+1))
+	}
+	return ""
+}
+
+// hugs reports the emphasis style markers. Code keeps its exact content, and
+// block, mention and hashtag markers do not depend on the surrounding text.
+func (markdownMarkup) hugs(e models.Entity) bool {
+	switch e.Type {
+	case models.EntityBold, models.EntityItalic, models.EntityUnderline,
+		models.EntityStrikethrough, models.EntitySpoiler, models.EntityLink:
+		return true
+	}
+	return false
+}
+
+func (markdownMarkup) escape(s string, active []models.Entity) string {
+	if !inside(active, models.EntityCode, models.EntityPre, models.EntityMention, models.EntityHashtag) {
+		s = markdownEscaper.Replace(s)
+	}
+	if inside(active, models.EntityBlockquote) {
+		s = strings.ReplaceAll(s, "\n", "\n> ")
+	}
+	return s
+}
+
+func codeFence(body string) string {
+	return strings.Repeat("`", longestRun(body, '`')+1)
+}
+
+// codePadding returns the space a code span needs so that its content
+// survives the parser: one space is stripped from both ends of a span when
+// present on both, and a span cannot start or end with a backtick.
+func codePadding(body string) string {
+	if body == "" {
+		return ""
+	}
+	first, last := body[0], body[len(body)-1]
+	if first == '`' || last == '`' || first == ' ' && last == ' ' && strings.Trim(body, " ") != "" {
+		return " "
+	}
+	return ""
+}
+
+func longestRun(s string, ch byte) int {
+	longest, run := 0, 0
+	for i := 0; i < len(s); i++ {
+		if s[i] != ch {
+			run = 0
+			continue
+		}
+		run++
+		longest = max(longest, run)
+	}
+	return longest
+}
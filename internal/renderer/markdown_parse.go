@@ -0,0 +1,666 @@
+package renderer
+
+import (
+	"blog-api/internal/models"
+	"blog-api/internal/validator"
+	"blog-api/pkg/textoffset"
+	"fmt"
+	"html"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MarkdownError points at the construct the parser refused to convert.
+type MarkdownError struct {
+	Line int
+	Msg  string
+}
+
+func (e *MarkdownError) Error() string {
+	return fmt.Sprintf("markdown: line %d: %s", e.Line, e.Msg)
+}
+
+var (
+	languageRe = regexp.MustCompile(`^[A-Za-z0-9_+#.-]{1,30}$`)
+	entityRe   = regexp.MustCompile(`^&(?:[A-Za-z][A-Za-z0-9]{1,31}|#[0-9]{1,7}|#[xX][0-9A-Fa-f]{1,6});`)
+)
+
+type delimiter struct {
+	marker string
+	typ    string
+	start  int
+	line   int
+}
+
+type markdownParser struct {
+	src      string
+	pos      int
+	line     int
+	out      strings.Builder
+	open     []delimiter
+	quote    *delimiter
+	entities []models.Entity
+}
+
+// ParseMarkdown converts the Markdown subset produced by Markdown back into
+// plain text plus entities: **bold** / __bold__, *italic* / _italic_,
+// <u>underline</u>, ~~strikethrough~~, ||spoiler||, [links](https://...),
+// `code`, fenced code blocks, > block quotes, @mentions and #hashtags.
+// Mentions are returned without a user ID, it is up to the caller to resolve
+// them. Anything that has no entity counterpart (headings, lists, nested
+// quotes, images, tables, raw HTML, HTML entities) is rejected instead of
+// being silently dropped or kept as literal text. Setext heading underlines
+// are the exception and stay text, since Markdown writes a line of = in plain
+// text unescaped. Entity offsets are returned in UTF-16 code units.
+func ParseMarkdown(src string) (string, []models.Entity, error) {
+	p := &markdownParser{
+		src:  strings.ReplaceAll(src, "\r\n", "\n"),
+		line: 1,
+	}
+
+	if err := p.parse(); err != nil {
+		return "", nil, err
+	}
+
+	text := p.out.String()
+	for i, e := range p.entities {
+		offset, length, err := textoffset.Convert(text, e.Offset, e.Length, textoffset.Byte, textoffset.UTF16)
+		if err != nil {
+			return "", nil, err
+		}
+		p.entities[i].Offset, p.entities[i].Length = offset, length
+	}
+	return text, p.entities, nil
+}
+
+func (p *markdownParser) fail(format string, args ...any) error {
+	return &MarkdownError{Line: p.line, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *markdownParser) parse() error {
+	for p.pos < len(p.src) {
+		if p.pos == 0 || p.src[p.pos-1] == '\n' {
+			if err := p.startLine(); err != nil {
+				return err
+			}
+			if p.pos >= len(p.src) {
+				break
+			}
+		}
+
+		rest := p.src[p.pos:]
+		switch {
+		case rest[0] == '\n':
+			p.out.WriteByte('\n')
+			p.pos++
+			p.line++
+
+		case rest[0] == '\\' && len(rest) > 1 && isASCIIPunct(rest[1]):
+			p.out.WriteByte(rest[1])
+			p.pos += 2
+
+		case rest[0] == '`':
+			if err := p.codeSpan(); err != nil {
+				return err
+			}
+
+		case strings.HasPrefix(rest, "!["):
+			return p.fail("images are not supported")
+
+		case strings.HasPrefix(rest, "~~"):
+			if p.isOpen("~~") {
+				if err := p.closeDelimiter("~~"); err != nil {
+					return err
+				}
+			} else {
+				p.openDelimiter("~~", models.EntityStrikethrough)
+			}
+			p.pos += 2
+
+		case strings.HasPrefix(rest, "<u>"):
+			p.openDelimiter("<u>", models.EntityUnderline)
+			p.pos += len("<u>")
+
+		case strings.HasPrefix(rest, "</u>"):
+			if err := p.closeDelimiter("<u>"); err != nil {
+				return err
+			}
+			p.pos += len("</u>")
+
+		case rest[0] == '<' && len(rest) > 1 && (isASCIILetter(rest[1]) || rest[1] == '/' || rest[1] == '!'):
+			return p.fail("raw HTML and autolinks are not supported")
+
+		case rest[0] == '&' && isEntity(rest):
+			return p.fail("HTML entities are not supported, write the character itself")
+
+		case strings.HasPrefix(rest, "||"):
+			if p.isOpen("||") {
+				if err := p.closeDelimiter("||"); err != nil {
+					return err
+				}
+			} else {
+				p.openDelimiter("||", models.EntitySpoiler)
+			}
+			p.pos += 2
+
+		case rest[0] == '[':
+			if p.isOpen("[") {
+				return p.fail("nested links are not supported")
+			}
+			p.openDelimiter("[", models.EntityLink)
+			p.pos++
+
+		case rest[0] == ']':
+			if err := p.closeLink(); err != nil {
+				return err
+			}
+
+		case rest[0] == '*' || rest[0] == '_':
+			p.emphasis()
+
+		case rest[0] == '@' && p.tag('@', models.EntityMention):
+		case rest[0] == '#' && p.tag('#', models.EntityHashtag):
+
+		default:
+			_, size := utf8.DecodeRuneInString(rest)
+			p.out.WriteString(rest[:size])
+			p.pos += size
+		}
+	}
+
+	if len(p.open) > 0 {
+		d := p.open[len(p.open)-1]
+		return &MarkdownError{Line: d.line, Msg: fmt.Sprintf("unclosed %q", d.marker)}
+	}
+	return p.closeQuote()
+}
+
+// startLine handles block level constructs at the start of a line: it opens,
+// continues and closes block quotes, consumes fenced code blocks and rejects
+// everything else.
+func (p *markdownParser) startLine() error {
+	line := p.currentLine()
+	if strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "\t") {
+		return p.fail("indented code blocks are not supported")
+	}
+
+	trimmed := strings.TrimLeft(line, " ")
+	if strings.HasPrefix(trimmed, ">") {
+		if p.quote == nil {
+			if len(p.open) > 0 {
+				return p.fail("formatting cannot span into a block quote")
+			}
+			p.quote = &delimiter{marker: ">", typ: models.EntityBlockquote, start: p.out.Len(), line: p.line}
+		}
+
+		trimmed = strings.TrimPrefix(trimmed[1:], " ")
+		p.pos += len(line) - len(trimmed)
+		if strings.HasPrefix(strings.TrimLeft(trimmed, " "), ">") {
+			return p.fail("nested block quotes are not supported")
+		}
+		if isFence(strings.TrimLeft(trimmed, " ")) {
+			return p.fail("code blocks inside block quotes are not supported")
+		}
+		return p.checkBlock(trimmed)
+	}
+
+	if err := p.closeQuote(); err != nil {
+		return err
+	}
+	if isFence(trimmed) {
+		return p.fencedCode(line, trimmed)
+	}
+	return p.checkBlock(line)
+}
+
+// checkBlock rejects block level constructs that have no entity counterpart.
+func (p *markdownParser) checkBlock(line string) error {
+	if strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "\t") {
+		return p.fail("indented code blocks are not supported")
+	}
+
+	trimmed := strings.TrimLeft(line, " ")
+	switch {
+	case trimmed == "":
+		return nil
+	case headingPrefix(trimmed):
+		return p.fail("headings are not supported")
+	case thematicBreak(trimmed):
+		return p.fail("horizontal rules are not supported")
+	case listPrefix(trimmed):
+		return p.fail("lists are not supported")
+	case trimmed[0] == '|' && !strings.HasPrefix(trimmed, "||"):
+		return p.fail("tables are not supported")
+	}
+	return nil
+}
+
+func (p *markdownParser) currentLine() string {
+	line := p.src[p.pos:]
+	if i := strings.IndexByte(line, '\n'); i >= 0 {
+		line = line[:i]
+	}
+	return line
+}
+
+// closeQuote ends the open block quote, if any, before the line break that
+// precedes the current line.
+func (p *markdownParser) closeQuote() error {
+	if p.quote == nil {
+		return nil
+	}
+
+	q := *p.quote
+	p.quote = nil
+	for _, d := range p.open {
+		if d.start >= q.start {
+			return &MarkdownError{Line: d.line, Msg: fmt.Sprintf("%q cannot continue past the end of a block quote", d.marker)}
+		}
+	}
+
+	end := p.out.Len()
+	if end > q.start && strings.HasSuffix(p.out.String(), "\n") {
+		end--
+	}
+	if end > q.start {
+		p.entities = append(p.entities, models.Entity{
+			Offset: q.start,
+			Length: end - q.start,
+			Type:   q.typ,
+		})
+	}
+	return nil
+}
+
+// fencedCode consumes a ``` or ~~~ code block up to its closing fence and
+// emits its content verbatim as a pre entity.
+func (p *markdownParser) fencedCode(line, trimmed string) error {
+	if len(p.open) > 0 {
+		return p.fail("formatting cannot span a code block")
+	}
+
+	ch := trimmed[0]
+	n := len(trimmed) - len(strings.TrimLeft(trimmed, string(ch)))
+	info := strings.TrimSpace(trimmed[n:])
+	if ch == '`' && strings.Contains(info, "`") {
+		return p.fail("invalid code block info string")
+	}
+
+	var language *string
+	if fields := strings.Fields(info); len(fields) > 0 {
+		if !IsLanguage(fields[0]) {
+			return p.fail("invalid code block language %q", fields[0])
+		}
+		language = &fields[0]
+	}
+
+	startLine := p.line
+	p.pos += len(line)
+
+	var body []string
+	closed := false
+	for p.pos < len(p.src) {
+		p.pos++
+		p.line++
+
+		l := p.currentLine()
+		p.pos += len(l)
+
+		t := strings.TrimLeft(l, " ")
+		if run := len(t) - len(strings.TrimLeft(t, string(ch))); run >= n && strings.TrimSpace(t[run:]) == "" {
+			closed = true
+			break
+		}
+		body = append(body, l)
+	}
+
+	if !closed {
+		return &MarkdownError{Line: startLine, Msg: "unclosed code block"}
+	}
+
+	content := strings.Join(body, "\n")
+	if content == "" {
+		return &MarkdownError{Line: startLine, Msg: "empty code block"}
+	}
+
+	p.entities = append(p.entities, models.Entity{
+		Offset:   p.out.Len(),
+		Length:   len(content),
+		Type:     models.EntityPre,
+		Language: language,
+	})
+	p.out.WriteString(content)
+	return nil
+}
+
+// codeSpan consumes an inline code span closed by a backtick run of the same
+// length and emits its content verbatim.
+func (p *markdownParser) codeSpan() error {
+	n := len(p.src[p.pos:]) - len(strings.TrimLeft(p.src[p.pos:], "`"))
+	rest := p.src[p.pos+n:]
+
+	end := -1
+	for i := 0; i < len(rest); {
+		j := strings.IndexByte(rest[i:], '`')
+		if j < 0 {
+			break
+		}
+		j += i
+		k := j
+		for k < len(rest) && rest[k] == '`' {
+			k++
+		}
+		if k-j == n {
+			end = j
+			break
+		}
+		i = k
+	}
+	if end < 0 {
+		return p.fail("unclosed inline code")
+	}
+
+	content := rest[:end]
+	if strings.Contains(content, "\n") {
+		return p.fail("inline code cannot span lines, use a code block")
+	}
+	if len(content) >= 2 && content[0] == ' ' && content[len(content)-1] == ' ' && strings.Trim(content, " ") != "" {
+		content = content[1 : len(content)-1]
+	}
+	if content == "" {
+		return p.fail("empty inline code")
+	}
+
+	p.entities = append(p.entities, models.Entity{
+		Offset: p.out.Len(),
+		Length: len(content),
+		Type:   models.EntityCode,
+	})
+	p.out.WriteString(content)
+	p.pos += n + end + n
+	return nil
+}
+
+// tag consumes a @mention or #hashtag starting at a word boundary. It reports
+// false when the text that follows is not a valid username or tag, in which
+// case the sign is literal.
+func (p *markdownParser) tag(sign byte, typ string) bool {
+	if before, _ := p.around(1); isWordRune(before) || before == '_' {
+		return false
+	}
+
+	rest := p.src[p.pos+1:]
+	n := 0
+	for n < len(rest) {
+		r, size := utf8.DecodeRuneInString(rest[n:])
+		if !isWordRune(r) && r != '_' {
+			break
+		}
+		n += size
+	}
+
+	name := rest[:n]
+	if sign == '@' {
+		name = strings.TrimRight(name, "_")
+		if !validator.IsUsername(name) {
+			return false
+		}
+	} else if r, _ := utf8.DecodeRuneInString(name); !isWordRune(r) {
+		return false
+	}
+
+	p.entities = append(p.entities, models.Entity{
+		Offset: p.out.Len(),
+		Length: 1 + len(name),
+		Type:   typ,
+	})
+	p.out.WriteByte(sign)
+	p.out.WriteString(name)
+	p.pos += 1 + len(name)
+	return true
+}
+
+func (p *markdownParser) emphasis() {
+	ch := p.src[p.pos]
+	single, double := string(ch), string([]byte{ch, ch})
+	n := len(p.src[p.pos:]) - len(strings.TrimLeft(p.src[p.pos:], single))
+	if n > 2 {
+		p.emphasisRun(n)
+		return
+	}
+
+	marker, typ := single, models.EntityItalic
+	if n == 2 {
+		marker, typ = double, models.EntityBold
+	}
+
+	canOpen, canClose := p.flanking(n)
+	switch {
+	case canClose && p.isOpen(marker):
+		_ = p.closeDelimiter(marker)
+	case canOpen:
+		p.openDelimiter(marker, typ)
+	default:
+		p.out.WriteString(marker)
+	}
+	p.pos += n
+}
+
+// emphasisRun handles a run of three or more markers, which Markdown writes
+// where markers meet, e.g. *** for bold italic text or **** for an italic
+// span closed and reopened around the end of a bold one. Closers are matched
+// first, and a marker the run both closes and opens continues its span.
+func (p *markdownParser) emphasisRun(n int) {
+	ch := p.src[p.pos]
+	single, double := string(ch), string([]byte{ch, ch})
+	canOpen, canClose := p.flanking(n)
+
+	left := n
+	var closing, opening []string
+	if canClose {
+		if p.isOpen(double) {
+			closing = append(closing, double)
+			left -= 2
+		}
+		if p.isOpen(single) {
+			closing = append(closing, single)
+			left--
+		}
+	}
+	if canOpen {
+		if left >= 2 {
+			opening = append(opening, double)
+			left -= 2
+		}
+		if left >= 1 {
+			opening = append(opening, single)
+			left--
+		}
+	}
+
+	for _, marker := range closing {
+		if i := slices.Index(opening, marker); i >= 0 {
+			opening = slices.Delete(opening, i, i+1)
+			continue
+		}
+		_ = p.closeDelimiter(marker)
+	}
+	for _, marker := range opening {
+		typ := models.EntityItalic
+		if marker == double {
+			typ = models.EntityBold
+		}
+		p.openDelimiter(marker, typ)
+	}
+	p.out.WriteString(strings.Repeat(single, left))
+	p.pos += n
+}
+
+// flanking reports whether the run of n emphasis markers at the current
+// position can open or close a span.
+func (p *markdownParser) flanking(n int) (canOpen, canClose bool) {
+	before, after := p.around(n)
+	canOpen = after != 0 && !unicode.IsSpace(after)
+	canClose = before != 0 && !unicode.IsSpace(before)
+	if p.src[p.pos] == '_' {
+		// snake_case words never toggle formatting
+		canOpen = canOpen && !isWordRune(before)
+		canClose = canClose && !isWordRune(after)
+	}
+	return canOpen, canClose
+}
+
+func (p *markdownParser) closeLink() error {
+	if !p.isOpen("[") {
+		return p.fail("unexpected \"]\", escape literal brackets as \\[ and \\]")
+	}
+
+	rest := p.src[p.pos+1:]
+	if !strings.HasPrefix(rest, "(") {
+		return p.fail("reference-style links are not supported")
+	}
+
+	end := strings.IndexByte(rest, ')')
+	if end < 0 {
+		return p.fail("unclosed link destination")
+	}
+
+	url := rest[1:end]
+	if strings.ContainsAny(url, " \t\n") {
+		return p.fail("link titles and spaces in link destinations are not supported")
+	}
+	if safeURL(&url) == "#" {
+		return p.fail("link destination must be an absolute http(s) url")
+	}
+
+	d := p.remove("[")
+	if p.out.Len() > d.start {
+		p.entities = append(p.entities, models.Entity{
+			Offset: d.start,
+			Length: p.out.Len() - d.start,
+			Type:   d.typ,
+			URL:    &url,
+		})
+	}
+
+	p.pos += 1 + end + 1
+	return nil
+}
+
+func (p *markdownParser) openDelimiter(marker, typ string) {
+	p.open = append(p.open, delimiter{
+		marker: marker,
+		typ:    typ,
+		start:  p.out.Len(),
+		line:   p.line,
+	})
+}
+
+func (p *markdownParser) closeDelimiter(marker string) error {
+	if !p.isOpen(marker) {
+		return p.fail("unexpected closing %q", marker)
+	}
+
+	d := p.remove(marker)
+	if p.out.Len() > d.start {
+		p.entities = append(p.entities, models.Entity{
+			Offset: d.start,
+			Length: p.out.Len() - d.start,
+			Type:   d.typ,
+		})
+	}
+	return nil
+}
+
+func (p *markdownParser) isOpen(marker string) bool {
+	for _, d := range p.open {
+		if d.marker == marker {
+			return true
+		}
+	}
+	return false
+}
+
+// remove pops the innermost open delimiter with the given marker. Entities
+// may overlap, so it does not have to be on top of the stack.
+func (p *markdownParser) remove(marker string) delimiter {
+	for i := len(p.open) - 1; i >= 0; i-- {
+		if p.open[i].marker == marker {
+			d := p.open[i]
+			p.open = append(p.open[:i], p.open[i+1:]...)
+			return d
+		}
+	}
+	return delimiter{}
+}
+
+func (p *markdownParser) around(width int) (before, after rune) {
+	if p.pos > 0 {
+		before, _ = utf8.DecodeLastRuneInString(p.src[:p.pos])
+	}
+	if p.pos+width < len(p.src) {
+		after, _ = utf8.DecodeRuneInString(p.src[p.pos+width:])
+	}
+	return before, after
+}
+
+// IsLanguage reports whether s is acceptable as a code block language.
+func IsLanguage(s string) bool {
+	return languageRe.MatchString(s)
+}
+
+func isFence(line string) bool {
+	return strings.HasPrefix(line, "```") || strings.HasPrefix(line, "~~~")
+}
+
+func headingPrefix(line string) bool {
+	n := 0
+	for n < len(line) && line[n] == '#' {
+		n++
+	}
+	return n > 0 && n <= 6 && (n == len(line) || line[n] == ' ')
+}
+
+// isEntity reports an HTML entity or numeric character reference at the
+// start of s. Unknown names such as &foo; are plain text.
+func isEntity(s string) bool {
+	ref := entityRe.FindString(s)
+	return ref != "" && html.UnescapeString(ref) != ref
+}
+
+func thematicBreak(line string) bool {
+	compact := strings.ReplaceAll(line, " ", "")
+	if len(compact) < 3 {
+		return false
+	}
+	return strings.Count(compact, "-") == len(compact) ||
+		strings.Count(compact, "*") == len(compact) ||
+		strings.Count(compact, "_") == len(compact)
+}
+
+func listPrefix(line string) bool {
+	if len(line) >= 2 && strings.ContainsRune("-*+", rune(line[0])) && line[1] == ' ' {
+		return true
+	}
+
+	n := 0
+	for n < len(line) && n < 9 && line[n] >= '0' && line[n] <= '9' {
+		n++
+	}
+	return n > 0 && n+1 < len(line) && (line[n] == '.' || line[n] == ')') && line[n+1] == ' '
+}
+
+func isASCIIPunct(b byte) bool {
+	return b < utf8.RuneSelf && unicode.IsPunct(rune(b)) || strings.IndexByte("$+<=>^`|~", b) >= 0
+}
+
+func isASCIILetter(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,256 @@
+package renderer
+
+import (
+	"blog-api/internal/models"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseMarkdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		text     string
+		entities []models.Entity
+	}{
+		{
+			name: "plain",
+			src:  "hello world",
+			text: "hello world",
+		},
+		{
+			name:     "bold",
+			src:      "**a** __b__",
+			text:     "a b",
+			entities: []models.Entity{{Offset: 0, Length: 1, Type: models.EntityBold}, {Offset: 2, Length: 1, Type: models.EntityBold}},
+		},
+		{
+			name:     "italic",
+			src:      "*a* _b_",
+			text:     "a b",
+			entities: []models.Entity{{Offset: 0, Length: 1, Type: models.EntityItalic}, {Offset: 2, Length: 1, Type: models.EntityItalic}},
+		},
+		{
+			name:     "intraword asterisks",
+			src:      "un*believ*able",
+			text:     "unbelievable",
+			entities: []models.Entity{{Offset: 2, Length: 6, Type: models.EntityItalic}},
+		},
+		{
+			name: "snake case",
+			src:  "snake_case_name",
+			text: "snake_case_name",
+		},
+		{
+			name: "lone markers",
+			src:  "a * b _ c",
+			text: "a * b _ c",
+		},
+		{
+			name: "bold italic",
+			src:  "***a***",
+			text: "a",
+			entities: []models.Entity{
+				{Offset: 0, Length: 1, Type: models.EntityBold},
+				{Offset: 0, Length: 1, Type: models.EntityItalic},
+			},
+		},
+		{
+			name: "underline strikethrough spoiler",
+			src:  "<u>u</u> ~~s~~ ||p||",
+			text: "u s p",
+			entities: []models.Entity{
+				{Offset: 0, Length: 1, Type: models.EntityUnderline},
+				{Offset: 2, Length: 1, Type: models.EntityStrikethrough},
+				{Offset: 4, Length: 1, Type: models.EntitySpoiler},
+			},
+		},
+		{
+			name:     "link",
+			src:      "see [the **docs**](https://example.com/a?b=1)",
+			text:     "see the docs",
+			entities: []models.Entity{{Offset: 4, Length: 8, Type: models.EntityLink, URL: ptr("https://example.com/a?b=1")}, {Offset: 8, Length: 4, Type: models.EntityBold}},
+		},
+		{
+			name:     "code",
+			src:      "run `a*b` now",
+			text:     "run a*b now",
+			entities: []models.Entity{{Offset: 4, Length: 3, Type: models.EntityCode}},
+		},
+		{
+			name:     "code with backticks",
+			src:      "`` `x` ``",
+			text:     "`x`",
+			entities: []models.Entity{{Offset: 0, Length: 3, Type: models.EntityCode}},
+		},
+		{
+			name:     "fenced code",
+			src:      "```go\nfmt.Println(\"*\")\n```",
+			text:     "fmt.Println(\"*\")",
+			entities: []models.Entity{{Offset: 0, Length: 16, Type: models.EntityPre, Language: ptr("go")}},
+		},
+		{
+			name:     "tilde fence",
+			src:      "~~~\na\n\nb\n~~~",
+			text:     "a\n\nb",
+			entities: []models.Entity{{Offset: 0, Length: 4, Type: models.EntityPre}},
+		},
+		{
+			name:     "blockquote",
+			src:      "> one\n> **two**\nthree",
+			text:     "one\ntwo\nthree",
+			entities: []models.Entity{{Offset: 0, Length: 7, Type: models.EntityBlockquote}, {Offset: 4, Length: 3, Type: models.EntityBold}},
+		},
+		{
+			name: "mention and hashtag",
+			src:  "hi @alice #go",
+			text: "hi @alice #go",
+			entities: []models.Entity{
+				{Offset: 3, Length: 6, Type: models.EntityMention},
+				{Offset: 10, Length: 3, Type: models.EntityHashtag},
+			},
+		},
+		{
+			name: "signs that are not tags",
+			src:  "mail@example.com C# @ab",
+			text: "mail@example.com C# @ab",
+		},
+		{
+			name: "escapes",
+			src:  `\*a\* \_b\_ \[c\] \& \\`,
+			text: `*a* _b_ [c] & \`,
+		},
+		{
+			name: "ampersands",
+			src:  "AT&T & &foo; &amp",
+			text: "AT&T & &foo; &amp",
+		},
+		{
+			name: "setext underline",
+			src:  "Title\n===",
+			text: "Title\n===",
+		},
+		{
+			name:     "crlf",
+			src:      "**a**\r\nb",
+			text:     "a\nb",
+			entities: []models.Entity{{Offset: 0, Length: 1, Type: models.EntityBold}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, entities, err := ParseMarkdown(tt.src)
+			if err != nil {
+				t.Fatalf("ParseMarkdown: %v", err)
+			}
+			if text != tt.text {
+				t.Errorf("text = %q, want %q", text, tt.text)
+			}
+			if got, want := sortEntities(entities), sortEntities(tt.entities); !reflect.DeepEqual(got, want) {
+				t.Errorf("entities = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestParseMarkdownRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		line int
+		msg  string
+	}{
+		{name: "heading", src: "# Title", line: 1, msg: "headings are not supported"},
+		{name: "bullet list", src: "a\n\n- item", line: 3, msg: "lists are not supported"},
+		{name: "ordered list", src: "1. item", line: 1, msg: "lists are not supported"},
+		{name: "horizontal rule", src: "***", line: 1, msg: "horizontal rules are not supported"},
+		{name: "table", src: "| a | b |", line: 1, msg: "tables are not supported"},
+		{name: "indented code", src: "    code", line: 1, msg: "indented code blocks are not supported"},
+		{name: "nested quote", src: "> > a", line: 1, msg: "nested block quotes are not supported"},
+		{name: "code in quote", src: "> ```\n> a\n> ```", line: 1, msg: "code blocks inside block quotes are not supported"},
+		{name: "image", src: "![alt](https://example.com/a.png)", line: 1, msg: "images are not supported"},
+		{name: "raw html", src: "<b>a</b>", line: 1, msg: "raw HTML and autolinks are not supported"},
+		{name: "autolink", src: "<https://example.com>", line: 1, msg: "raw HTML and autolinks are not supported"},
+		{name: "named entity", src: "a &amp; b", line: 1, msg: "HTML entities are not supported"},
+		{name: "decimal entity", src: "&#169;", line: 1, msg: "HTML entities are not supported"},
+		{name: "hex entity", src: "ok\n&#x1F600;", line: 2, msg: "HTML entities are not supported"},
+		{name: "reference link", src: "[a][ref]", line: 1, msg: "reference-style links are not supported"},
+		{name: "link title", src: `[a](https://example.com "title")`, line: 1, msg: "link titles"},
+		{name: "relative link", src: "[a](/path)", line: 1, msg: "absolute http(s) url"},
+		{name: "nested link", src: "[a [b](https://example.com)](https://example.com)", line: 1, msg: "nested links are not supported"},
+		{name: "stray bracket", src: "a]", line: 1, msg: `unexpected "]"`},
+		{name: "unclosed bold", src: "ok\n**a", line: 2, msg: `unclosed "**"`},
+		{name: "unclosed code", src: "`a", line: 1, msg: "unclosed inline code"},
+		{name: "multiline code", src: "`a\nb`", line: 1, msg: "inline code cannot span lines"},
+		{name: "unclosed code block", src: "a\n```\ncode", line: 2, msg: "unclosed code block"},
+		{name: "empty code block", src: "```\n```", line: 1, msg: "empty code block"},
+		{name: "invalid language", src: "```a!b\nx\n```", line: 1, msg: "invalid code block language"},
+		{name: "formatting past quote", src: "> **a\nb**", line: 1, msg: "cannot continue past the end of a block quote"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := ParseMarkdown(tt.src)
+
+			var mdErr *MarkdownError
+			if !errors.As(err, &mdErr) {
+				t.Fatalf("ParseMarkdown error = %v, want a MarkdownError", err)
+			}
+			if mdErr.Line != tt.line || !strings.Contains(mdErr.Msg, tt.msg) {
+				t.Errorf("ParseMarkdown error = %v, want line %d: %s", err, tt.line, tt.msg)
+			}
+		})
+	}
+}
+
+func TestParseMarkdownOffsets(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		text     string
+		entities []models.Entity
+	}{
+		{
+			name:     "two byte characters",
+			src:      "héllo **wörld**",
+			text:     "héllo wörld",
+			entities: []models.Entity{{Offset: 6, Length: 5, Type: models.EntityBold}},
+		},
+		{
+			name:     "surrogate pairs",
+			src:      "😀 *a😀b* `c`",
+			text:     "😀 a😀b c",
+			entities: []models.Entity{{Offset: 3, Length: 4, Type: models.EntityItalic}, {Offset: 8, Length: 1, Type: models.EntityCode}},
+		},
+		{
+			name:     "code block after emoji line",
+			src:      "🎉\n```\n𝒳\n```",
+			text:     "🎉\n𝒳",
+			entities: []models.Entity{{Offset: 3, Length: 2, Type: models.EntityPre}},
+		},
+		{
+			name:     "mention after cjk",
+			src:      "你好 @alice",
+			text:     "你好 @alice",
+			entities: []models.Entity{{Offset: 3, Length: 6, Type: models.EntityMention}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, entities, err := ParseMarkdown(tt.src)
+			if err != nil {
+				t.Fatalf("ParseMarkdown: %v", err)
+			}
+			if text != tt.text {
+				t.Errorf("text = %q, want %q", text, tt.text)
+			}
+			if got, want := sortEntities(entities), sortEntities(tt.entities); !reflect.DeepEqual(got, want) {
+				t.Errorf("entities = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
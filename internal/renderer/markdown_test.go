@@ -0,0 +1,221 @@
+package renderer
+
+import (
+	"blog-api/internal/models"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+var markdownTests = []struct {
+	name     string
+	text     string
+	entities []models.Entity
+	want     string
+	// parsed is what ParseMarkdown reads back from want when it is not
+	// entities, because Markdown cannot express them exactly
+	parsed []models.Entity
+}{
+	{
+		name: "plain",
+		text: "hello world",
+		want: "hello world",
+	},
+	{
+		name: "escaped",
+		text: `1*2_3 [a](b) #x \ ~ &amp;`,
+		want: `1\*2\_3 \[a\]\(b\) \#x \\ \~ \&amp;`,
+	},
+	{
+		name:     "bold",
+		text:     "hello world",
+		entities: []models.Entity{{Offset: 6, Length: 5, Type: models.EntityBold}},
+		want:     "hello **world**",
+	},
+	{
+		name:     "italic",
+		text:     "hello world",
+		entities: []models.Entity{{Offset: 0, Length: 5, Type: models.EntityItalic}},
+		want:     "*hello* world",
+	},
+	{
+		name:     "intraword italic",
+		text:     "unbelievable",
+		entities: []models.Entity{{Offset: 2, Length: 6, Type: models.EntityItalic}},
+		want:     "un*believ*able",
+	},
+	{
+		name:     "intraword bold",
+		text:     "unbelievable",
+		entities: []models.Entity{{Offset: 2, Length: 6, Type: models.EntityBold}},
+		want:     "un**believ**able",
+	},
+	{
+		name: "bold italic",
+		text: "both",
+		entities: []models.Entity{
+			{Offset: 0, Length: 4, Type: models.EntityBold},
+			{Offset: 0, Length: 4, Type: models.EntityItalic},
+		},
+		want: "***both***",
+	},
+	{
+		name: "italic inside bold",
+		text: "abc",
+		entities: []models.Entity{
+			{Offset: 0, Length: 3, Type: models.EntityBold},
+			{Offset: 1, Length: 1, Type: models.EntityItalic},
+		},
+		want: "**a*b*c**",
+	},
+	{
+		name: "overlapping",
+		text: "abc",
+		entities: []models.Entity{
+			{Offset: 0, Length: 2, Type: models.EntityBold},
+			{Offset: 1, Length: 2, Type: models.EntityItalic},
+		},
+		want: "**a*b****c*",
+	},
+	{
+		name: "overlapping reversed",
+		text: "abc",
+		entities: []models.Entity{
+			{Offset: 0, Length: 2, Type: models.EntityItalic},
+			{Offset: 1, Length: 2, Type: models.EntityBold},
+		},
+		want: "*a**b*****c**",
+	},
+	{
+		name: "bold inside italic word",
+		text: "abc",
+		entities: []models.Entity{
+			{Offset: 0, Length: 3, Type: models.EntityItalic},
+			{Offset: 1, Length: 1, Type: models.EntityBold},
+		},
+		want: "*a**b**c*",
+	},
+	{
+		name: "adjacent bold and italic",
+		text: "ab",
+		entities: []models.Entity{
+			{Offset: 0, Length: 1, Type: models.EntityBold},
+			{Offset: 1, Length: 1, Type: models.EntityItalic},
+		},
+		want: "**a***b*",
+	},
+	{
+		name:     "whitespace moved outside markers",
+		text:     "a b c",
+		entities: []models.Entity{{Offset: 1, Length: 3, Type: models.EntityItalic}},
+		want:     "a *b* c",
+		parsed:   []models.Entity{{Offset: 2, Length: 1, Type: models.EntityItalic}},
+	},
+	{
+		name: "underline strikethrough spoiler",
+		text: "u s p",
+		entities: []models.Entity{
+			{Offset: 0, Length: 1, Type: models.EntityUnderline},
+			{Offset: 2, Length: 1, Type: models.EntityStrikethrough},
+			{Offset: 4, Length: 1, Type: models.EntitySpoiler},
+		},
+		want: "<u>u</u> ~~s~~ ||p||",
+	},
+	{
+		name:     "link",
+		text:     "see docs",
+		entities: []models.Entity{{Offset: 4, Length: 4, Type: models.EntityLink, URL: ptr("https://example.com/a(b)")}},
+		want:     "see [docs](https://example.com/a%28b%29)",
+		parsed:   []models.Entity{{Offset: 4, Length: 4, Type: models.EntityLink, URL: ptr("https://example.com/a%28b%29")}},
+	},
+	{
+		name:     "code",
+		text:     "run a*b",
+		entities: []models.Entity{{Offset: 4, Length: 3, Type: models.EntityCode}},
+		want:     "run `a*b`",
+	},
+	{
+		name:     "code with backticks",
+		text:     "x `y` z",
+		entities: []models.Entity{{Offset: 0, Length: 7, Type: models.EntityCode}},
+		want:     "``x `y` z``",
+	},
+	{
+		name:     "pre",
+		text:     "fmt.Println()",
+		entities: []models.Entity{{Offset: 0, Length: 13, Type: models.EntityPre, Language: ptr("go")}},
+		want:     "```go\nfmt.Println()\n```",
+	},
+	{
+		name:     "blockquote",
+		text:     "one\ntwo",
+		entities: []models.Entity{{Offset: 0, Length: 7, Type: models.EntityBlockquote}},
+		want:     "> one\n> two",
+	},
+	{
+		name: "mention and hashtag",
+		text: "hi @alice #go",
+		entities: []models.Entity{
+			{Offset: 3, Length: 6, Type: models.EntityMention},
+			{Offset: 10, Length: 3, Type: models.EntityHashtag},
+		},
+		want: "hi @alice #go",
+	},
+	{
+		name:     "utf16 offsets",
+		text:     "😀 héllo",
+		entities: []models.Entity{{Offset: 3, Length: 5, Type: models.EntityItalic}},
+		want:     "😀 *héllo*",
+	},
+}
+
+func TestMarkdown(t *testing.T) {
+	for _, tt := range markdownTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Markdown(tt.text, tt.entities); got != tt.want {
+				t.Errorf("Markdown() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkdownRoundTrip(t *testing.T) {
+	for _, tt := range markdownTests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, entities, err := ParseMarkdown(Markdown(tt.text, tt.entities))
+			if err != nil {
+				t.Fatalf("ParseMarkdown: %v", err)
+			}
+			if text != tt.text {
+				t.Errorf("text = %q, want %q", text, tt.text)
+			}
+			want := tt.entities
+			if tt.parsed != nil {
+				want = tt.parsed
+			}
+			if got, want := sortEntities(entities), sortEntities(want); !reflect.DeepEqual(got, want) {
+				t.Errorf("entities = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+// sortEntities orders entities the way normalize does, so that lists built
+// in a different order compare equal.
+func sortEntities(entities []models.Entity) []models.Entity {
+	sorted := append([]models.Entity{}, entities...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Offset != sorted[j].Offset {
+			return sorted[i].Offset < sorted[j].Offset
+		}
+		if sorted[i].Length != sorted[j].Length {
+			return sorted[i].Length > sorted[j].Length
+		}
+		return sorted[i].Type < sorted[j].Type
+	})
+	return sorted
+}
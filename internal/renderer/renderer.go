@@ -0,0 +1,193 @@
+package renderer
+
+import (
+	"blog-api/internal/models"
+	"blog-api/pkg/textoffset"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	FormatHTML     = "html"
+	FormatMarkdown = "markdown"
+	FormatText     = "text"
+)
+
+// markup describes how a single output format opens and closes entities and
+// escapes plain text between them. body is the text covered by the entity and
+// active lists the entities enclosing s, innermost last. Markers that have to
+// hug the text (Markdown emphasis cannot start or end with a space) are
+// reported by hugs, and surrounding whitespace is moved outside of them.
+type markup interface {
+	open(e models.Entity, body string) string
+	close(e models.Entity, body string) string
+	escape(s string, active []models.Entity) string
+	hugs(e models.Entity) bool
+}
+
+// Render formats text with entities whose offsets are measured in UTF-16 code
+// units, the unit posts store them in.
+func Render(text string, entities []models.Entity, format string) (string, error) {
+	switch format {
+	case FormatHTML:
+		return HTML(text, entities), nil
+	case FormatMarkdown:
+		return Markdown(text, entities), nil
+	case FormatText:
+		return Text(text, entities), nil
+	}
+	return "", fmt.Errorf("unsupported format: %s", format)
+}
+
+func HTML(text string, entities []models.Entity) string {
+	return render(text, entities, htmlMarkup{})
+}
+
+func Markdown(text string, entities []models.Entity) string {
+	return render(text, entities, markdownMarkup{})
+}
+
+func Text(text string, _ []models.Entity) string {
+	return text
+}
+
+// render walks the text boundary by boundary. Entities may nest or overlap;
+// when an entity ends while entities opened after it are still active, those
+// are closed first and reopened right after, so the output is always well
+// formed.
+func render(text string, entities []models.Entity, m markup) string {
+	spans := normalize(text, entities)
+	if len(spans) == 0 {
+		return m.escape(text, nil)
+	}
+
+	boundaries := make([]int, 0, len(spans)*2+2)
+	boundaries = append(boundaries, 0, len(text))
+	for _, s := range spans {
+		boundaries = append(boundaries, s.Offset, s.Offset+s.Length)
+	}
+	sort.Ints(boundaries)
+	boundaries = slices.Compact(boundaries)
+
+	var (
+		out   = &output{m: m}
+		stack []models.Entity
+		next  int // index of the next span to open
+		prev  int
+	)
+
+	for _, pos := range boundaries {
+		if pos > prev {
+			out.text(text[prev:pos], stack)
+			prev = pos
+		}
+
+		// close entities ending here, remembering the ones that outlive them
+		var reopen []models.Entity
+		for i := len(stack) - 1; i >= 0; i-- {
+			if stack[i].Offset+stack[i].Length > pos {
+				continue
+			}
+			for j := len(stack) - 1; j >= i; j-- {
+				out.close(stack[j], body(text, stack[j]))
+				if j > i && stack[j].Offset+stack[j].Length > pos {
+					reopen = append([]models.Entity{stack[j]}, reopen...)
+				}
+			}
+			stack = stack[:i]
+		}
+		for _, e := range reopen {
+			out.open(e, body(text, e))
+			stack = append(stack, e)
+		}
+
+		for next < len(spans) && spans[next].Offset == pos {
+			out.open(spans[next], body(text, spans[next]))
+			stack = append(stack, spans[next])
+			next++
+		}
+	}
+
+	for i := len(stack) - 1; i >= 0; i-- {
+		out.close(stack[i], body(text, stack[i]))
+	}
+	return out.String()
+}
+
+func body(text string, e models.Entity) string {
+	return text[e.Offset : e.Offset+e.Length]
+}
+
+type output struct {
+	m        markup
+	b        strings.Builder
+	opening  strings.Builder // hugging markers waiting for the next non-space text
+	trailing string          // escaped whitespace held back until markers are closed
+}
+
+func (o *output) open(e models.Entity, body string) {
+	switch {
+	case o.m.hugs(e), o.opening.Len() > 0:
+		o.opening.WriteString(o.m.open(e, body))
+	default:
+		o.flush()
+		o.b.WriteString(o.m.open(e, body))
+	}
+}
+
+func (o *output) close(e models.Entity, body string) {
+	if !o.m.hugs(e) {
+		o.flush()
+	}
+	o.b.WriteString(o.m.close(e, body))
+}
+
+func (o *output) text(s string, active []models.Entity) {
+	body := strings.TrimLeftFunc(s, unicode.IsSpace)
+	o.flush()
+	o.b.WriteString(o.m.escape(s[:len(s)-len(body)], active))
+	o.b.WriteString(o.opening.String())
+	o.opening.Reset()
+
+	trimmed := strings.TrimRightFunc(body, unicode.IsSpace)
+	o.b.WriteString(o.m.escape(trimmed, active))
+	o.trailing = o.m.escape(body[len(trimmed):], active)
+}
+
+func (o *output) flush() {
+	o.b.WriteString(o.trailing)
+	o.trailing = ""
+}
+
+func (o *output) String() string {
+	return o.b.String() + o.trailing
+}
+
+// normalize converts entity offsets from UTF-16 code units to byte indices,
+// drops empty, out of range and character-splitting entities and orders the
+// rest so that outer entities open before inner ones.
+func normalize(text string, entities []models.Entity) []models.Entity {
+	spans := make([]models.Entity, 0, len(entities))
+	for _, e := range entities {
+		if e.Length <= 0 {
+			continue
+		}
+		offset, length, err := textoffset.Convert(text, e.Offset, e.Length, textoffset.UTF16, textoffset.Byte)
+		if err != nil {
+			continue
+		}
+		e.Offset, e.Length = offset, length
+		spans = append(spans, e)
+	}
+
+	sort.SliceStable(spans, func(i, j int) bool {
+		if spans[i].Offset != spans[j].Offset {
+			return spans[i].Offset < spans[j].Offset
+		}
+		return spans[i].Length > spans[j].Length
+	})
+	return spans
+}
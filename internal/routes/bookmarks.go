@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"blog-api/internal/bookmarks"
+	"blog-api/internal/middleware"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func RegisterBookmarkRoutes(r fiber.Router, h bookmarks.IBookmarkHandler, mw *middleware.Manager) {
+	r.Post("/", mw.AuthMiddleware(), h.AddBookmark)
+	r.Get("/", mw.AuthMiddleware(), h.GetBookmarks)
+	r.Delete("/:post_id<int>", mw.AuthMiddleware(), h.RemoveBookmark)
+
+	r.Post("/collections", mw.AuthMiddleware(), h.CreateCollection)
+	r.Get("/collections", mw.AuthMiddleware(), h.GetCollections)
+	r.Put("/collections/:id<int>", mw.AuthMiddleware(), h.RenameCollection)
+	r.Delete("/collections/:id<int>", mw.AuthMiddleware(), h.DeleteCollection)
+}
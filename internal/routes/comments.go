@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"blog-api/internal/comments"
+	"blog-api/internal/middleware"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func RegisterCommentRoutes(r fiber.Router, h comments.ICommentHandler, mw *middleware.Manager) {
+	r.Post("/", mw.AuthMiddleware(), h.CreateComment)
+	r.Get("/:id<int>", mw.OptionalAuthMiddleware(), h.GetComment)
+	r.Get("/", mw.OptionalAuthMiddleware(), h.GetComments)
+	r.Put("/:id<int>", mw.AuthMiddleware(), h.UpdateComment)
+	r.Delete("/:id<int>", mw.AuthMiddleware(), h.DeleteComment)
+}
@@ -0,0 +1,12 @@
+package routes
+
+import (
+	"blog-api/internal/feed"
+	"blog-api/internal/middleware"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func RegisterFeedRoutes(r fiber.Router, h feed.IFeedHandler, mw *middleware.Manager) {
+	r.Get("/", mw.AuthMiddleware(), h.GetFeed)
+}
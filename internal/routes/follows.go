@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"blog-api/internal/follows"
+	"blog-api/internal/middleware"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func RegisterFollowRoutes(r fiber.Router, h follows.IFollowHandler, mw *middleware.Manager) {
+	r.Post("/:id<int>/follow", mw.AuthMiddleware(), h.Follow)
+	r.Delete("/:id<int>/follow", mw.AuthMiddleware(), h.Unfollow)
+	r.Get("/:id<int>/followers", h.GetFollowers)
+	r.Get("/:id<int>/following", h.GetFollowing)
+}
@@ -9,4 +9,11 @@ import (
 
 func RegisterPhotoRoutes(r fiber.Router, h photos.IPhotoHandler, mw *middleware.Manager) {
 	r.Post("/avatar", mw.AuthMiddleware(), h.UploadAvatar)
+	r.Delete("/avatar", mw.AuthMiddleware(), h.DeleteAvatar)
+	r.Get("/avatar/history", mw.AuthMiddleware(), h.GetAvatarHistory)
+	r.Post("/avatar/history/:id/revert", mw.AuthMiddleware(), h.RevertAvatar)
+	r.Post("/posts", mw.AuthMiddleware(), h.UploadPostMedia)
+	r.Post("/uploads", mw.AuthMiddleware(), h.CreateUpload)
+	r.Post("/uploads/:id/confirm", mw.AuthMiddleware(), h.ConfirmUpload)
+	r.Get("/usage", mw.AuthMiddleware(), h.GetUsage)
 }
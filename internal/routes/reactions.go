@@ -10,4 +10,14 @@ import (
 func RegisterReactionRoutes(r fiber.Router, h reactions.IReactionHandler, mw *middleware.Manager) {
 	r.Get("/available", h.GetAvailableReactions)
 	r.Post("/posts", mw.AuthMiddleware(), h.SetPostReaction)
+	r.Post("/comments", mw.AuthMiddleware(), h.SetCommentReaction)
+
+	r.Get("/types", mw.AuthMiddleware(), mw.AdminMiddleware(), h.ListReactionTypes)
+	r.Post("/types", mw.AuthMiddleware(), mw.AdminMiddleware(), h.CreateReactionType)
+	r.Patch("/types/:id<int>", mw.AuthMiddleware(), mw.AdminMiddleware(), h.UpdateReactionType)
+	r.Put("/types/order", mw.AuthMiddleware(), mw.AdminMiddleware(), h.ReorderReactionTypes)
+
+	r.Get("/:target_type/:id<int>", mw.OptionalAuthMiddleware(), h.GetReactions)
+	r.Post("/:target_type/:id<int>", mw.AuthMiddleware(), h.SetReaction)
+	r.Get("/:target_type/:id<int>/users", h.GetReactors)
 }
@@ -0,0 +1,138 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// clamChunkSize is the size of the chunks files are streamed to clamd in.
+const clamChunkSize = 64 << 10
+
+// ClamAV scans files with clamd, streaming them over its INSTREAM command so
+// that clamd does not need access to the files.
+type ClamAV struct {
+	network string
+	address string
+	timeout time.Duration
+}
+
+// NewClamAV connects to clamd at addr, given as tcp://host:port or
+// unix:///path/to/clamd.sock.
+func NewClamAV(addr string, timeout time.Duration) (*ClamAV, error) {
+	u, err := url.Parse(addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid clamd address: %w", err)
+	}
+
+	c := &ClamAV{network: u.Scheme, timeout: timeout}
+	switch u.Scheme {
+	case "tcp":
+		c.address = u.Host
+	case "unix":
+		c.address = u.Path
+	default:
+		return nil, fmt.Errorf("invalid clamd address %q: scheme must be tcp or unix", addr)
+	}
+	if c.address == "" {
+		return nil, fmt.Errorf("invalid clamd address %q", addr)
+	}
+	return c, nil
+}
+
+func (c *ClamAV) Scan(ctx context.Context, r io.Reader) (Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, c.network, c.address)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to connect to clamd: %w", err)
+	}
+	defer conn.Close()
+
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		return Result{}, err
+	}
+	// unblock reads and writes when the caller gives up
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	sendErr := c.send(conn, r)
+
+	// clamd answers and hangs up early when the stream exceeds its
+	// StreamMaxLength, so the reply is worth reading even if sending failed
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil {
+		if sendErr != nil {
+			return Result{}, fmt.Errorf("failed to send file to clamd: %w", sendErr)
+		}
+		return Result{}, fmt.Errorf("failed to read clamd reply: %w", err)
+	}
+	return parseClamReply(strings.TrimSuffix(reply, "\x00"))
+}
+
+// send streams r as INSTREAM chunks, each prefixed with its length as a
+// 4 byte big endian integer and terminated by an empty chunk.
+func (c *ClamAV) send(conn net.Conn, r io.Reader) error {
+	w := bufio.NewWriterSize(conn, clamChunkSize+4)
+	if _, err := w.WriteString("zINSTREAM\x00"); err != nil {
+		return err
+	}
+
+	buf := make([]byte, clamChunkSize)
+	var size [4]byte
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size[:], uint32(n))
+			if _, err := w.Write(size[:]); err != nil {
+				return err
+			}
+			if _, err := w.Write(buf[:n]); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	binary.BigEndian.PutUint32(size[:], 0)
+	if _, err := w.Write(size[:]); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// parseClamReply reads replies of the form "stream: OK",
+// "stream: <signature> FOUND" and "<message> ERROR".
+func parseClamReply(reply string) (Result, error) {
+	reply = strings.TrimSpace(reply)
+
+	if msg, ok := strings.CutSuffix(reply, " ERROR"); ok {
+		return Result{}, fmt.Errorf("clamd: %s", msg)
+	}
+
+	status, ok := strings.CutPrefix(reply, "stream: ")
+	if !ok {
+		return Result{}, fmt.Errorf("unexpected clamd reply: %q", reply)
+	}
+	if status == "OK" {
+		return Result{}, nil
+	}
+	if signature, ok := strings.CutSuffix(status, " FOUND"); ok {
+		return Result{Infected: true, Signature: signature}, nil
+	}
+	return Result{}, fmt.Errorf("unexpected clamd reply: %q", reply)
+}
@@ -0,0 +1,195 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeClamd accepts a single connection, reads an INSTREAM request from it
+// and answers with reply. With limit > 0 it answers and hangs up as soon as
+// more than limit bytes were streamed, like clamd does past StreamMaxLength.
+type fakeClamd struct {
+	reply string
+	limit int
+
+	// filled in once the connection is handled
+	command string
+	chunks  []int
+	data    []byte
+	done    chan error
+}
+
+func startFakeClamd(t *testing.T, reply string, limit int) (*fakeClamd, string) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	f := &fakeClamd{reply: reply, limit: limit, done: make(chan error, 1)}
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			f.done <- err
+			return
+		}
+		defer conn.Close()
+		f.done <- f.handle(conn)
+	}()
+	return f, "tcp://" + ln.Addr().String()
+}
+
+func (f *fakeClamd) handle(conn net.Conn) error {
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	command := make([]byte, len("zINSTREAM\x00"))
+	if _, err := io.ReadFull(conn, command); err != nil {
+		return err
+	}
+	f.command = string(command)
+
+	var size [4]byte
+	for {
+		if _, err := io.ReadFull(conn, size[:]); err != nil {
+			return err
+		}
+		n := binary.BigEndian.Uint32(size[:])
+		f.chunks = append(f.chunks, int(n))
+		if n == 0 {
+			break
+		}
+
+		chunk := make([]byte, n)
+		if _, err := io.ReadFull(conn, chunk); err != nil {
+			return err
+		}
+		f.data = append(f.data, chunk...)
+
+		if f.limit > 0 && len(f.data) > f.limit {
+			break
+		}
+	}
+
+	_, err := conn.Write([]byte(f.reply + "\x00"))
+	return err
+}
+
+func (f *fakeClamd) wait(t *testing.T) {
+	t.Helper()
+	select {
+	case err := <-f.done:
+		if err != nil {
+			t.Fatalf("fake clamd: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("fake clamd did not finish")
+	}
+}
+
+func newTestClamAV(t *testing.T, addr string) *ClamAV {
+	t.Helper()
+	c, err := NewClamAV(addr, 5*time.Second)
+	if err != nil {
+		t.Fatalf("NewClamAV: %v", err)
+	}
+	return c
+}
+
+func TestClamAVStreamFraming(t *testing.T) {
+	f, addr := startFakeClamd(t, "stream: OK", 0)
+
+	data := bytes.Repeat([]byte("0123456789abcdef"), (2*clamChunkSize+100)/16)
+	if _, err := newTestClamAV(t, addr).Scan(context.Background(), bytes.NewReader(data)); err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	f.wait(t)
+
+	if f.command != "zINSTREAM\x00" {
+		t.Errorf("command = %q, want %q", f.command, "zINSTREAM\x00")
+	}
+	want := []int{clamChunkSize, clamChunkSize, len(data) - 2*clamChunkSize, 0}
+	if len(f.chunks) != len(want) {
+		t.Fatalf("chunk lengths = %v, want %v", f.chunks, want)
+	}
+	for i := range want {
+		if f.chunks[i] != want[i] {
+			t.Fatalf("chunk lengths = %v, want %v", f.chunks, want)
+		}
+	}
+	if !bytes.Equal(f.data, data) {
+		t.Error("streamed data differs from the input")
+	}
+}
+
+func TestClamAVReplies(t *testing.T) {
+	tests := []struct {
+		name    string
+		reply   string
+		want    Result
+		wantErr string
+	}{
+		{
+			name:  "clean",
+			reply: "stream: OK",
+			want:  Result{},
+		},
+		{
+			name:  "infected",
+			reply: "stream: Eicar-Test-Signature FOUND",
+			want:  Result{Infected: true, Signature: "Eicar-Test-Signature"},
+		},
+		{
+			name:    "error",
+			reply:   "Can't allocate memory ERROR",
+			wantErr: "clamd: Can't allocate memory",
+		},
+		{
+			name:    "unexpected",
+			reply:   "PONG",
+			wantErr: "unexpected clamd reply",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, addr := startFakeClamd(t, tt.reply, 0)
+
+			got, err := newTestClamAV(t, addr).Scan(context.Background(), strings.NewReader("file"))
+			f.wait(t)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Scan error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Scan: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Scan = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClamAVStreamLimitExceeded(t *testing.T) {
+	f, addr := startFakeClamd(t, "INSTREAM size limit exceeded. ERROR", clamChunkSize)
+
+	// large enough for the writes to fail once clamd has hung up
+	data := bytes.Repeat([]byte{0}, 8<<20)
+	_, err := newTestClamAV(t, addr).Scan(context.Background(), bytes.NewReader(data))
+	f.wait(t)
+
+	if err == nil || !strings.Contains(err.Error(), "INSTREAM size limit exceeded") {
+		t.Fatalf("Scan error = %v, want the clamd reply", err)
+	}
+}
@@ -0,0 +1,40 @@
+package scanner
+
+import (
+	"blog-api/config"
+	"context"
+	"fmt"
+	"io"
+)
+
+// Result is the verdict on a scanned file.
+type Result struct {
+	Infected bool
+	// Signature names what was found in an infected file.
+	Signature string
+}
+
+// Scanner checks uploaded files for malware before they are stored. An error
+// means no verdict could be reached, not that the file is infected.
+type Scanner interface {
+	Scan(ctx context.Context, r io.Reader) (Result, error)
+}
+
+func New(cfg config.ScannerConfig) (Scanner, error) {
+	switch cfg.Backend {
+	case config.ScannerNone:
+		return Noop{}, nil
+	case config.ScannerClamAV:
+		return NewClamAV(cfg.ClamAVAddress, cfg.Timeout)
+	default:
+		return nil, fmt.Errorf("unknown scanner backend: %s", cfg.Backend)
+	}
+}
+
+// Noop accepts every file. It is the default for deployments without a
+// scanner.
+type Noop struct{}
+
+func (Noop) Scan(context.Context, io.Reader) (Result, error) {
+	return Result{}, nil
+}
@@ -0,0 +1,39 @@
+package server
+
+import (
+	"blog-api/internal/logger"
+	"context"
+	"log/slog"
+	"time"
+)
+
+// job is background work run every interval while the server is up.
+type job struct {
+	name     string
+	interval time.Duration
+	run      func(ctx context.Context) error
+}
+
+func (s *Server) startJobs(ctx context.Context) {
+	for _, j := range s.jobs {
+		go s.runJob(ctx, j)
+	}
+}
+
+func (s *Server) runJob(ctx context.Context, j job) {
+	log := s.Logger.With(slog.String("job", j.name))
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := j.run(ctx); err != nil && ctx.Err() == nil {
+				log.Error("background job failed", logger.Err(err))
+			}
+		}
+	}
+}
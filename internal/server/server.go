@@ -3,14 +3,19 @@ package server
 import (
 	"blog-api/config"
 	"blog-api/internal/auth"
+	"blog-api/internal/bookmarks"
+	"blog-api/internal/comments"
 	"blog-api/internal/database"
 	"blog-api/internal/errors"
+	"blog-api/internal/feed"
+	"blog-api/internal/follows"
 	"blog-api/internal/logger"
 	"blog-api/internal/middleware"
 	"blog-api/internal/photos"
 	"blog-api/internal/posts"
 	"blog-api/internal/reactions"
 	"blog-api/internal/routes"
+	"blog-api/internal/scanner"
 	"blog-api/internal/storage"
 	"blog-api/internal/tokenmanager"
 	"blog-api/internal/users"
@@ -27,19 +32,21 @@ import (
 	"github.com/gofiber/fiber/v3"
 	"github.com/gofiber/fiber/v3/middleware/recover"
 	"github.com/gofiber/fiber/v3/middleware/requestid"
+	"github.com/gofiber/fiber/v3/middleware/static"
 )
 
 type Dependencies struct {
 	Cfg         *config.Config
 	DB          *database.DB
 	RedisClient *storage.RedisClient
-	MinioClient *storage.MinioClient
+	ObjectStore storage.ObjectStore
 	Validator   *validator.Validate
 	Logger      *slog.Logger
 }
 
 type Server struct {
-	app *fiber.App
+	app  *fiber.App
+	jobs []job
 	*Dependencies
 }
 
@@ -48,22 +55,40 @@ func NewServer(deps *Dependencies) (*Server, error) {
 		panic("NewServer: deps cannot be nil")
 	}
 
+	urls, err := storage.NewURLResolver(deps.ObjectStore, deps.Cfg.StorageConfig, deps.Logger)
+	if err != nil {
+		return nil, err
+	}
+
+	fileScanner, err := scanner.New(deps.Cfg.ScannerConfig)
+	if err != nil {
+		return nil, err
+	}
+
 	// Services
 	jwtService := tokenmanager.NewJWTManager(deps.Cfg.SecretKey, deps.Cfg.JwtConfig)
-	userService := users.NewUserService(deps.DB, deps.Logger)
+	userService := users.NewUserService(deps.DB, urls, deps.Logger)
 	authService := auth.NewAuthService(jwtService, deps.DB, deps.RedisClient, deps.Logger)
-	postService := posts.NewPostService(deps.DB, deps.Logger)
-	photoService := photos.NewPhotoService(deps.DB, deps.MinioClient, deps.Logger)
-	reactionService := reactions.NewReactionService(deps.DB, deps.Logger)
+	photoService := photos.NewPhotoService(deps.DB, deps.ObjectStore, urls, fileScanner, deps.Cfg.MediaConfig, deps.Logger)
+	postService := posts.NewPostService(deps.DB, deps.RedisClient, photoService, deps.Logger)
+	reactionService := reactions.NewReactionService(deps.DB, urls, reactions.DefaultTargets(deps.Cfg.ReactionsConfig), deps.Logger)
+	commentService := comments.NewCommentService(deps.DB, urls, deps.Cfg.CommentsConfig, deps.Logger)
+	followService := follows.NewFollowService(deps.DB, urls, deps.Logger)
+	feedService := feed.NewFeedService(feed.NewPostgresTimeline(deps.DB), postService, deps.Logger)
+	bookmarkService := bookmarks.NewBookmarkService(deps.DB, postService, deps.Logger)
 
 	mw := middleware.NewManager(deps.Logger, jwtService, userService)
 
 	// Handlers
 	authHandler := auth.NewAuthHandler(authService)
-	userHandler := users.NewUserHandler()
+	userHandler := users.NewUserHandler(userService)
 	postHandler := posts.NewPostHandler(postService)
 	photoHandler := photos.NewPhotoHandler(photoService)
 	reactionHandler := reactions.NewReactionHandler(reactionService)
+	commentHandler := comments.NewCommentHandler(commentService)
+	followHandler := follows.NewFollowHandler(followService)
+	feedHandler := feed.NewFeedHandler(feedService)
+	bookmarkHandler := bookmarks.NewBookmarkHandler(bookmarkService)
 
 	// App
 	app := fiber.New(fiber.Config{
@@ -80,6 +105,16 @@ func NewServer(deps *Dependencies) (*Server, error) {
 	}))
 	app.Use(mw.LoggerMiddleware())
 
+	// Uploaded files, when the API keeps them itself
+	if storageCfg := deps.Cfg.StorageConfig; storageCfg.Backend == config.StorageLocal {
+		path, serve := storageCfg.LocalURLPrefix+"*", static.New(storageCfg.LocalDir)
+		if local, ok := deps.ObjectStore.(*storage.LocalStore); ok && storageCfg.SignedURLs {
+			app.Get(path, mw.SignedURLMiddleware(local, storageCfg.LocalURLPrefix), serve)
+		} else {
+			app.Get(path, serve)
+		}
+	}
+
 	// Groups
 	apiGroup := app.Group("/api")
 	authGroup := apiGroup.Group("/auth")
@@ -87,16 +122,47 @@ func NewServer(deps *Dependencies) (*Server, error) {
 	postsGroup := apiGroup.Group("/posts")
 	photosGroup := apiGroup.Group("/photos")
 	reactionsGroup := apiGroup.Group("/reactions")
+	commentsGroup := apiGroup.Group("/comments")
+	feedGroup := apiGroup.Group("/feed")
+	bookmarksGroup := apiGroup.Group("/bookmarks")
 
 	// Routes
 	routes.RegisterAuthRoutes(authGroup, authHandler, mw)
 	routes.RegisterUserRoutes(usersGroup, userHandler, mw)
+	routes.RegisterFollowRoutes(usersGroup, followHandler, mw)
 	routes.RegisterPostRoutes(postsGroup, postHandler, mw)
 	routes.RegisterPhotoRoutes(photosGroup, photoHandler, mw)
 	routes.RegisterReactionRoutes(reactionsGroup, reactionHandler, mw)
+	routes.RegisterCommentRoutes(commentsGroup, commentHandler, mw)
+	routes.RegisterFeedRoutes(feedGroup, feedHandler, mw)
+	routes.RegisterBookmarkRoutes(bookmarksGroup, bookmarkHandler, mw)
+
+	// Background jobs
+	jobs := []job{
+		{
+			name:     "cleanup expired uploads",
+			interval: deps.Cfg.MediaConfig.UploadCleanupInterval,
+			run: func(ctx context.Context) error {
+				if _, err := photoService.CleanupExpiredUploads(ctx); err != nil {
+					return err
+				}
+				_, err := photoService.CleanupUnattachedMedia(ctx)
+				return err
+			},
+		},
+		{
+			name:     "collect orphaned objects",
+			interval: deps.Cfg.MediaConfig.GCInterval,
+			run: func(ctx context.Context) error {
+				_, err := photoService.CollectGarbage(ctx, deps.Cfg.MediaConfig.GCDryRun)
+				return err
+			},
+		},
+	}
 
 	return &Server{
 		app:          app,
+		jobs:         jobs,
 		Dependencies: deps,
 	}, nil
 }
@@ -105,6 +171,10 @@ func (s *Server) Run() error {
 	shutdownChan := make(chan os.Signal, 1)
 	signal.Notify(shutdownChan, os.Interrupt, syscall.SIGTERM, syscall.SIGINT)
 
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	s.startJobs(jobsCtx)
+
 	serverErr := make(chan error, 1)
 	go func() {
 		addr := fmt.Sprintf("%s:%s", s.Cfg.ServerConfig.Host, s.Cfg.ServerConfig.Port)
@@ -121,6 +191,7 @@ func (s *Server) Run() error {
 	select {
 	case err1 := <-serverErr:
 		s.Logger.Error("Server error", slog.Any("error", err1))
+		stopJobs()
 		err2 := s.DB.Close()
 		return goerrors.Join(err1, err2)
 
@@ -143,6 +214,8 @@ func (s *Server) Run() error {
 			s.Logger.Info("Server stopped gracefully")
 		}
 
+		stopJobs()
+
 		if err := s.DB.Close(); err != nil {
 			s.Logger.Error("Database close error", logger.Err(err))
 		}
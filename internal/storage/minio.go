@@ -3,6 +3,9 @@ package storage
 import (
 	"blog-api/config"
 	"context"
+	"fmt"
+	"io"
+	"time"
 
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
@@ -11,6 +14,8 @@ import (
 type MinioClient struct {
 	Client *minio.Client
 	Bucket string
+
+	baseURL string // public address of the bucket, if not the endpoint
 }
 
 func NewMinioClient(cfg config.MinioConfig) (*MinioClient, error) {
@@ -37,3 +42,88 @@ func NewMinioClient(cfg config.MinioConfig) (*MinioClient, error) {
 
 	return &MinioClient{Client: minioClient, Bucket: cfg.Bucket}, nil
 }
+
+func (c *MinioClient) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	_, err := c.Client.PutObject(ctx, c.Bucket, key, r, size, minio.PutObjectOptions{
+		ContentType: contentType,
+	})
+	return err
+}
+
+func (c *MinioClient) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	obj, err := c.Client.GetObject(ctx, c.Bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	// GetObject is lazy, Stat is what actually reaches the server
+	if _, err := obj.Stat(); err != nil {
+		obj.Close()
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, ErrObjectNotFound
+		}
+		return nil, err
+	}
+	return obj, nil
+}
+
+func (c *MinioClient) Delete(ctx context.Context, key string) error {
+	return c.Client.RemoveObject(ctx, c.Bucket, key, minio.RemoveObjectOptions{})
+}
+
+func (c *MinioClient) List(ctx context.Context, prefix string, fn func(ObjectInfo) error) error {
+	// stopping early requires cancelling the listing
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	for obj := range c.Client.ListObjects(ctx, c.Bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if obj.Err != nil {
+			return obj.Err
+		}
+		if err := fn(ObjectInfo{Key: obj.Key, Size: obj.Size, LastModified: obj.LastModified}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *MinioClient) URL(key string) string {
+	if c.baseURL != "" {
+		return c.baseURL + "/" + key
+	}
+	endpoint := c.Client.EndpointURL()
+	return fmt.Sprintf("%s://%s/%s/%s", endpoint.Scheme, endpoint.Host, c.Bucket, key)
+}
+
+func (c *MinioClient) SignedURL(ctx context.Context, key string, expiresAt time.Time) (string, error) {
+	url, err := c.Client.PresignedGetObject(ctx, c.Bucket, key, time.Until(expiresAt), nil)
+	if err != nil {
+		return "", err
+	}
+	return url.String(), nil
+}
+
+func (c *MinioClient) PresignUpload(ctx context.Context, key, contentType string, maxSize int64, expiresAt time.Time) (*PresignedUpload, error) {
+	policy := minio.NewPostPolicy()
+	if err := policy.SetBucket(c.Bucket); err != nil {
+		return nil, err
+	}
+	if err := policy.SetKey(key); err != nil {
+		return nil, err
+	}
+	if err := policy.SetContentType(contentType); err != nil {
+		return nil, err
+	}
+	if err := policy.SetContentLengthRange(1, maxSize); err != nil {
+		return nil, err
+	}
+	if err := policy.SetExpires(expiresAt); err != nil {
+		return nil, err
+	}
+
+	url, fields, err := c.Client.PresignedPostPolicy(ctx, policy)
+	if err != nil {
+		return nil, err
+	}
+	return &PresignedUpload{URL: url.String(), Fields: fields}, nil
+}
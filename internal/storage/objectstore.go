@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"blog-api/config"
+	"context"
+	goerrors "errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+var ErrObjectNotFound = goerrors.New("object not found")
+
+// ObjectStore keeps uploaded files. Keys are slash separated paths such as
+// "posts/1/<uuid>".
+type ObjectStore interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get returns ErrObjectNotFound when there is no object under key.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete succeeds when there is no object under key.
+	Delete(ctx context.Context, key string) error
+	// List calls fn for every object whose key starts with prefix, stopping
+	// at the first error fn returns.
+	List(ctx context.Context, prefix string, fn func(ObjectInfo) error) error
+	// URL is the public address clients download the object from.
+	URL(key string) string
+}
+
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+}
+
+// Presigner is implemented by stores clients can upload to directly.
+type Presigner interface {
+	// PresignUpload returns a form upload to key accepting a single file of
+	// contentType no larger than maxSize, valid until expiresAt.
+	PresignUpload(ctx context.Context, key, contentType string, maxSize int64, expiresAt time.Time) (*PresignedUpload, error)
+}
+
+// PresignedUpload is a multipart POST request: Fields have to be sent as form
+// values, followed by the file in a "file" field.
+type PresignedUpload struct {
+	URL    string
+	Fields map[string]string
+}
+
+// URLSigner is implemented by stores that can hand out time-limited URLs to
+// objects which are not publicly readable.
+type URLSigner interface {
+	SignedURL(ctx context.Context, key string, expiresAt time.Time) (string, error)
+}
+
+// NewObjectStore creates the configured backend. secret signs URLs of the
+// backends that do not have credentials of their own.
+func NewObjectStore(cfg config.StorageConfig, minioCfg config.MinioConfig, secret string) (ObjectStore, error) {
+	switch cfg.Backend {
+	case config.StorageMinio:
+		client, err := NewMinioClient(minioCfg)
+		if err != nil {
+			return nil, err
+		}
+		client.baseURL = strings.TrimSuffix(cfg.PublicBaseURL, "/")
+		return client, nil
+	case config.StorageLocal:
+		return NewLocalStore(cfg.LocalDir, publicPrefix(cfg), secret)
+	case config.StorageMemory:
+		return NewMemoryStore(publicPrefix(cfg)), nil
+	}
+	return nil, fmt.Errorf("unknown storage backend: %s", cfg.Backend)
+}
+
+// publicPrefix is where clients reach the objects the API serves itself.
+func publicPrefix(cfg config.StorageConfig) string {
+	if cfg.PublicBaseURL != "" {
+		return cfg.PublicBaseURL
+	}
+	return cfg.LocalURLPrefix
+}
@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"blog-api/config"
+	"blog-api/internal/logger"
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// URLResolver turns object keys into the URLs clients download objects from.
+type URLResolver interface {
+	URL(key string) string
+}
+
+// NewURLResolver returns the store itself when objects are public, and a
+// resolver signing every URL otherwise.
+func NewURLResolver(store ObjectStore, cfg config.StorageConfig, logger *slog.Logger) (URLResolver, error) {
+	if !cfg.SignedURLs {
+		return store, nil
+	}
+
+	signer, ok := store.(URLSigner)
+	if !ok {
+		return nil, fmt.Errorf("storage backend %s cannot sign URLs", cfg.Backend)
+	}
+	return &signedURLs{signer: signer, ttl: cfg.SignedURLTTL, logger: logger}, nil
+}
+
+type signedURLs struct {
+	signer URLSigner
+	ttl    time.Duration
+	logger *slog.Logger
+}
+
+// URL returns an empty string when signing fails, leaving the object without
+// a link rather than failing the whole response.
+func (u *signedURLs) URL(key string) string {
+	url, err := u.signer.SignedURL(context.Background(), key, time.Now().Add(u.ttl))
+	if err != nil {
+		u.logger.Error("failed to sign object url", slog.String("key", key), logger.Err(err))
+		return ""
+	}
+	return url
+}
@@ -1,9 +1,34 @@
 package users
 
+import "blog-api/internal/models"
+
 type UserResponse struct {
 	UserID   uint   `json:"user_id"`
 	Username string `json:"username"`
 	Email    string `json:"email"`
 	Deleted  bool   `json:"deleted"`
 	Avatar   string `json:"avatar"`
+
+	AvatarVariants      map[string]string `json:"avatar_variants,omitempty"`
+	AvatarBlurHash      string            `json:"avatar_blurhash,omitempty"`
+	AvatarDominantColor string            `json:"avatar_dominant_color,omitempty"`
+
+	// the avatar object key and variant sizes, turned into URLs by
+	// SetAvatarURLs
+	avatarKey      string
+	avatarVariants string
+
+	role string
+}
+
+// IsAdmin reports whether the user may use the admin endpoints.
+func (u *UserResponse) IsAdmin() bool {
+	return u.role == models.RoleAdmin
+}
+
+type ProfileResponse struct {
+	*UserResponse
+	FollowersCount int64 `json:"followers_count"`
+	FollowingCount int64 `json:"following_count"`
+	IsFollowing    bool  `json:"is_following"`
 }
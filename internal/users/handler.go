@@ -1,23 +1,64 @@
 package users
 
 import (
+	"blog-api/internal/logger"
 	"blog-api/pkg/response"
+	"context"
 
 	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/requestid"
 )
 
 type IUserHandler interface {
 	GetMe(ctx fiber.Ctx) error
+	GetProfile(ctx fiber.Ctx) error
 }
 
 type userHandler struct {
+	userService IUserService
 }
 
-func NewUserHandler() IUserHandler {
-	return &userHandler{}
+func NewUserHandler(userService IUserService) IUserHandler {
+	return &userHandler{
+		userService: userService,
+	}
 }
 
 func (h *userHandler) GetMe(ctx fiber.Ctx) error {
 	user := MustGetUser(ctx)
-	return ctx.JSON(response.NewResponse(user))
+	requestID := requestid.FromContext(ctx)
+
+	profile, err := h.userService.GetProfile(
+		context.WithValue(ctx, logger.RequestIDKey, requestID),
+		user.UserID,
+		&user.UserID,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(response.NewResponse(profile))
+}
+
+func (h *userHandler) GetProfile(ctx fiber.Ctx) error {
+	userID := fiber.Params[uint](ctx, "id")
+	requestID := requestid.FromContext(ctx)
+
+	var viewerID *uint
+	if viewer := GetUser(ctx); viewer != nil {
+		viewerID = &viewer.UserID
+	}
+
+	profile, err := h.userService.GetProfile(
+		context.WithValue(ctx, logger.RequestIDKey, requestID),
+		userID,
+		viewerID,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(response.NewResponse(profile))
 }
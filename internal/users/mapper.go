@@ -1,13 +1,54 @@
 package users
 
-import "blog-api/internal/models"
+import (
+	"blog-api/internal/models"
+	"strconv"
+	"strings"
+)
 
 func MapUserToResponse(user models.User) *UserResponse {
 	return &UserResponse{
-		UserID:   user.ID,
-		Username: user.Username,
-		Email:    user.Email.String,
-		Deleted:  user.DeletedAt.Valid,
-		Avatar:   user.Avatar.String,
+		UserID:         user.ID,
+		Username:       user.Username,
+		Email:          user.Email.String,
+		Deleted:        user.DeletedAt.Valid,
+		avatarKey:      user.Avatar.String,
+		avatarVariants: user.AvatarVariants,
+		role:           user.Role,
+
+		AvatarBlurHash:      user.AvatarBlurHash,
+		AvatarDominantColor: user.AvatarDominantColor,
+	}
+}
+
+// SetAvatarURLs fills the avatar URLs of mapped users. They depend on the
+// storage configuration, so the mapper only keeps the object keys.
+func SetAvatarURLs(url func(key string) string, result ...*UserResponse) {
+	for _, res := range result {
+		if res == nil || res.avatarKey == "" {
+			continue
+		}
+
+		res.Avatar = url(res.avatarKey)
+		res.AvatarVariants = MapAvatarVariants(url, res.avatarKey, res.avatarVariants)
+	}
+}
+
+// MapAvatarVariants derives the URLs of the avatar variants from the avatar
+// key, as variants are stored next to the original. sizes is the comma
+// separated list stored with the avatar.
+func MapAvatarVariants(url func(key string) string, key string, sizes string) map[string]string {
+	if sizes == "" {
+		return nil
+	}
+
+	variants := make(map[string]string)
+	for _, size := range strings.Split(sizes, ",") {
+		n, err := strconv.Atoi(size)
+		if err != nil {
+			continue
+		}
+		variants[size] = url(models.VariantKey(key, n))
 	}
+	return variants
 }
@@ -5,6 +5,7 @@ import (
 	"blog-api/internal/errors"
 	"blog-api/internal/logger"
 	"blog-api/internal/models"
+	"blog-api/internal/storage"
 	"context"
 	goerrors "errors"
 	"log/slog"
@@ -12,16 +13,19 @@ import (
 
 type IUserService interface {
 	GetUserByID(ctx context.Context, userID uint) (*UserResponse, error)
+	GetProfile(ctx context.Context, userID uint, viewerID *uint) (*ProfileResponse, error)
 }
 
 type userService struct {
 	db     *database.DB
+	urls   storage.URLResolver
 	logger *slog.Logger
 }
 
-func NewUserService(db *database.DB, logger *slog.Logger) IUserService {
+func NewUserService(db *database.DB, urls storage.URLResolver, logger *slog.Logger) IUserService {
 	return &userService{
 		db:     db,
+		urls:   urls,
 		logger: logger,
 	}
 }
@@ -40,5 +44,43 @@ func (s *userService) GetUserByID(ctx context.Context, userID uint) (*UserRespon
 		log.Error("database query failed", logger.Err(err))
 		return nil, err
 	}
-	return MapUserToResponse(user), nil
+
+	result := MapUserToResponse(user)
+	SetAvatarURLs(s.urls.URL, result)
+	return result, nil
+}
+
+func (s *userService) GetProfile(ctx context.Context, userID uint, viewerID *uint) (*ProfileResponse, error) {
+	db := s.db.WithContext(ctx)
+	log := logger.WithUserID(logger.FromCtx(ctx, s.logger), userID)
+
+	user, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := &ProfileResponse{UserResponse: user}
+
+	if err := db.Model(&models.Follow{}).Where("followee_id = ?", userID).Count(&profile.FollowersCount).Error; err != nil {
+		log.Error("failed to count followers", logger.Err(err))
+		return nil, err
+	}
+
+	if err := db.Model(&models.Follow{}).Where("follower_id = ?", userID).Count(&profile.FollowingCount).Error; err != nil {
+		log.Error("failed to count following", logger.Err(err))
+		return nil, err
+	}
+
+	if viewerID != nil && *viewerID != userID {
+		var following int64
+		if err := db.Model(&models.Follow{}).
+			Where("follower_id = ? AND followee_id = ?", *viewerID, userID).
+			Count(&following).Error; err != nil {
+			log.Error("failed to check follow relation", logger.Err(err))
+			return nil, err
+		}
+		profile.IsFollowing = following > 0
+	}
+
+	return profile, nil
 }
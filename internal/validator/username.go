@@ -20,6 +20,10 @@ func RegisterUsernameValidation(v *validator.Validate) error {
 		if !ok {
 			return false
 		}
-		return usernameRe.MatchString(s)
+		return IsUsername(s)
 	})
 }
+
+func IsUsername(s string) bool {
+	return usernameRe.MatchString(s)
+}
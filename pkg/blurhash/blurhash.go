@@ -0,0 +1,125 @@
+// Package blurhash encodes images as BlurHash strings, compact placeholders
+// clients decode into a blurred preview, see https://blurha.sh.
+package blurhash
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"strings"
+)
+
+const alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz#$%*+,-.:;=?@[]^_{|}~"
+
+// Encode computes the BlurHash of img with x by y components, each between 1
+// and 9. The cost grows with the pixel count, so img should be a thumbnail.
+func Encode(x, y int, img image.Image) (string, error) {
+	if x < 1 || x > 9 || y < 1 || y > 9 {
+		return "", fmt.Errorf("blurhash: components must be between 1 and 9, got %dx%d", x, y)
+	}
+
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width == 0 || height == 0 {
+		return "", fmt.Errorf("blurhash: empty image")
+	}
+
+	// linear RGB of every pixel, read once
+	pixels := make([][3]float64, width*height)
+	for py := 0; py < height; py++ {
+		for px := 0; px < width; px++ {
+			r, g, b, _ := img.At(bounds.Min.X+px, bounds.Min.Y+py).RGBA()
+			pixels[py*width+px] = [3]float64{toLinear(r >> 8), toLinear(g >> 8), toLinear(b >> 8)}
+		}
+	}
+
+	factors := make([][3]float64, 0, x*y)
+	for j := 0; j < y; j++ {
+		for i := 0; i < x; i++ {
+			factors = append(factors, factor(pixels, width, height, i, j))
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(encode83((x-1)+(y-1)*9, 1))
+
+	maximum := 1.0
+	if len(factors) > 1 {
+		var actual float64
+		for _, f := range factors[1:] {
+			actual = max(actual, math.Abs(f[0]), math.Abs(f[1]), math.Abs(f[2]))
+		}
+		quantised := int(max(0, min(82, math.Floor(actual*166-0.5))))
+		maximum = float64(quantised+1) / 166
+		b.WriteString(encode83(quantised, 1))
+	} else {
+		b.WriteString(encode83(0, 1))
+	}
+
+	b.WriteString(encode83(encodeDC(factors[0]), 4))
+	for _, f := range factors[1:] {
+		b.WriteString(encode83(encodeAC(f, maximum), 2))
+	}
+	return b.String(), nil
+}
+
+func factor(pixels [][3]float64, width, height, i, j int) [3]float64 {
+	var sum [3]float64
+	for py := 0; py < height; py++ {
+		for px := 0; px < width; px++ {
+			basis := math.Cos(math.Pi*float64(i)*float64(px)/float64(width)) *
+				math.Cos(math.Pi*float64(j)*float64(py)/float64(height))
+			p := pixels[py*width+px]
+			sum[0] += basis * p[0]
+			sum[1] += basis * p[1]
+			sum[2] += basis * p[2]
+		}
+	}
+
+	normalisation := 2.0
+	if i == 0 && j == 0 {
+		normalisation = 1
+	}
+	scale := normalisation / float64(width*height)
+	return [3]float64{sum[0] * scale, sum[1] * scale, sum[2] * scale}
+}
+
+func encodeDC(c [3]float64) int {
+	return toSRGB(c[0])<<16 + toSRGB(c[1])<<8 + toSRGB(c[2])
+}
+
+func encodeAC(c [3]float64, maximum float64) int {
+	quant := func(v float64) int {
+		return int(max(0, min(18, math.Floor(signPow(v/maximum, 0.5)*9+9.5))))
+	}
+	return quant(c[0])*19*19 + quant(c[1])*19 + quant(c[2])
+}
+
+func encode83(value, length int) string {
+	buf := make([]byte, length)
+	for i := length - 1; i >= 0; i-- {
+		buf[i] = alphabet[value%83]
+		value /= 83
+	}
+	return string(buf)
+}
+
+func toLinear(v uint32) float64 {
+	c := float64(v) / 255
+	if c <= 0.04045 {
+		return c / 12.92
+	}
+	return math.Pow((c+0.055)/1.055, 2.4)
+}
+
+func toSRGB(v float64) int {
+	v = max(0, min(1, v))
+	if v <= 0.0031308 {
+		return int(v*12.92*255 + 0.5)
+	}
+	return int((1.055*math.Pow(v, 1/2.4)-0.055)*255 + 0.5)
+}
+
+func signPow(v, exp float64) float64 {
+	return math.Copysign(math.Pow(math.Abs(v), exp), v)
+}
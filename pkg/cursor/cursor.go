@@ -0,0 +1,36 @@
+package cursor
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+)
+
+// CreatedAtID points at the last row of a page of a listing ordered by
+// (created_at, id), the order of every listing that cannot be sorted.
+type CreatedAtID struct {
+	CreatedAt time.Time `json:"t"`
+	ID        uint      `json:"id"`
+}
+
+func Encode[T any](v T) (string, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func Decode[T any](s string) (T, error) {
+	var v T
+
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return v, err
+	}
+
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return v, err
+	}
+	return v, nil
+}
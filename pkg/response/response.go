@@ -4,6 +4,10 @@ type Response[T any] struct {
 	OK   bool   `json:"ok"`
 	Msg  string `json:"msg"`
 	Data T      `json:"data"`
+
+	// set on errors that carry a machine readable reason
+	Reason  string `json:"reason,omitempty"`
+	Details any    `json:"details,omitempty"`
 }
 
 func (r *Response[T]) Error() string {
@@ -14,8 +18,10 @@ func (r *Response[T]) Error() string {
 }
 
 type Pagination[T any] struct {
-	Total  int64 `json:"total"`
-	Result []T   `json:"result"`
+	Total      *int64 `json:"total,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+	Result     []T    `json:"result"`
 }
 
 func NewResponse[T any](data T) Response[T] {
@@ -27,7 +33,16 @@ func NewResponse[T any](data T) Response[T] {
 
 func NewPaginatedResponse[T any](total int64, result []T) Response[Pagination[T]] {
 	return NewResponse(Pagination[T]{
-		Total:  total,
+		Total:  &total,
 		Result: result,
 	})
 }
+
+func NewCursorPaginatedResponse[T any](total *int64, nextCursor, prevCursor string, result []T) Response[Pagination[T]] {
+	return NewResponse(Pagination[T]{
+		Total:      total,
+		NextCursor: nextCursor,
+		PrevCursor: prevCursor,
+		Result:     result,
+	})
+}
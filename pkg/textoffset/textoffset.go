@@ -0,0 +1,131 @@
+package textoffset
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+type Unit string
+
+const (
+	Byte      Unit = "byte"
+	Codepoint Unit = "codepoint"
+	UTF16     Unit = "utf16"
+)
+
+func (u Unit) Valid() bool {
+	return u == Byte || u == Codepoint || u == UTF16
+}
+
+func width(r rune, unit Unit) int {
+	switch unit {
+	case Byte:
+		return utf8.RuneLen(r)
+	case UTF16:
+		if r >= 0x10000 {
+			return 2
+		}
+	}
+	return 1
+}
+
+// Len returns the length of text measured in unit.
+func Len(text string, unit Unit) int {
+	if unit == Byte {
+		return len(text)
+	}
+
+	n := 0
+	for _, r := range text {
+		n += width(r, unit)
+	}
+	return n
+}
+
+// ToBytes returns the byte index of position pos measured in unit. It fails
+// when pos is out of range or falls inside a character (e.g. between the two
+// halves of a UTF-16 surrogate pair).
+func ToBytes(text string, pos int, unit Unit) (int, bool) {
+	if pos < 0 {
+		return 0, false
+	}
+	if unit == Byte {
+		return pos, pos <= len(text) && (pos == len(text) || utf8.RuneStart(text[pos]))
+	}
+
+	n := 0
+	for i, r := range text {
+		if n == pos {
+			return i, true
+		}
+		if n > pos {
+			return 0, false
+		}
+		n += width(r, unit)
+	}
+	return len(text), n == pos
+}
+
+// FromBytes returns the position measured in unit of byte index i. It fails
+// when i is out of range or falls inside a UTF-8 sequence.
+func FromBytes(text string, i int, unit Unit) (int, bool) {
+	if i < 0 || i > len(text) || (i < len(text) && !utf8.RuneStart(text[i])) {
+		return 0, false
+	}
+	if unit == Byte {
+		return i, true
+	}
+	return Len(text[:i], unit), true
+}
+
+// Convert translates a span of text from one unit to another.
+func Convert(text string, offset, length int, from, to Unit) (int, int, error) {
+	if from == to {
+		return offset, length, nil
+	}
+
+	start, ok1 := ToBytes(text, offset, from)
+	end, ok2 := ToBytes(text, offset+length, from)
+	if !ok1 || !ok2 {
+		return 0, 0, fmt.Errorf("range %d+%d is out of bounds or splits a character", offset, length)
+	}
+
+	newOffset, _ := FromBytes(text, start, to)
+	newEnd, _ := FromBytes(text, end, to)
+	return newOffset, newEnd - newOffset, nil
+}
+
+// Snap converts a span like Convert but widens it to whole characters
+// instead of failing. It is meant for repairing legacy data.
+func Snap(text string, offset, length int, from, to Unit) (int, int) {
+	start, _ := bounds(text, offset, from, false)
+	end, _ := bounds(text, offset+length, from, true)
+
+	newOffset, _ := FromBytes(text, start, to)
+	newEnd, _ := FromBytes(text, end, to)
+	return newOffset, newEnd - newOffset
+}
+
+// bounds returns the byte index of the character boundary at or around pos,
+// rounding down or up when pos falls inside a character.
+func bounds(text string, pos int, unit Unit, up bool) (int, bool) {
+	if pos <= 0 {
+		return 0, pos == 0
+	}
+
+	n := 0
+	for i, r := range text {
+		w := width(r, unit)
+		switch {
+		case n == pos:
+			return i, true
+		case n < pos && pos < n+w:
+			if up {
+				return i + utf8.RuneLen(r), false
+			}
+			return i, false
+		}
+		n += w
+	}
+	return len(text), n == pos
+}